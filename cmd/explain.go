@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// runExplain implements the explain subcommand, which prints every
+// intermediate value of an expression such as "10 + 5 * 2" evaluated as a
+// task. It returns the process exit code.
+func runExplain(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, `usage: explain "10 + 5 * 2"`)
+		return 2
+	}
+
+	task, err := models.ParseExpression(strings.Join(args, " "))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	result := processor.Explain(task)
+	fmt.Fprintln(stdout, result.Explanation)
+	if result.Error != nil {
+		fmt.Fprintf(stderr, "error: %v (%s)\n", result.Error, models.CodeOf(result.Error))
+		return 1
+	}
+	fmt.Fprintf(stdout, "result: %d\n", result.Result)
+	return 0
+}
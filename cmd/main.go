@@ -2,42 +2,40 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"concurrent-pipeline-processor/internal/admin"
 	"concurrent-pipeline-processor/internal/config"
+	"concurrent-pipeline-processor/internal/ingest"
 	"concurrent-pipeline-processor/internal/logger"
+	"concurrent-pipeline-processor/internal/metrics"
 	"concurrent-pipeline-processor/internal/pipeline"
+	"concurrent-pipeline-processor/internal/tracing"
 	"concurrent-pipeline-processor/pkg/models"
 )
 
 func main() {
+	// Subcommands take their own arguments
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		os.Exit(runExplain(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// Parse command line flags
 	configFile := flag.String("config", "", "path to config file")
 	flag.Parse()
 
 	// Load configuration
-	cfg := config.DefaultConfig()
-
-	// Load from environment variables
-	cfg.LoadFromEnv()
-
-	// Load from config file if specified
-	if *configFile != "" {
-		if err := cfg.LoadFromFile(*configFile); err != nil {
-			fmt.Printf("Failed to load config file: %v\n", err)
-			os.Exit(1)
-		}
-	}
-
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		fmt.Printf("Invalid configuration: %v\n", err)
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		fmt.Printf("%v\n", err)
 		os.Exit(1)
 	}
 
@@ -49,119 +47,391 @@ func main() {
 		Pretty:     cfg.Service.PrettyLog,
 	})
 
-	log := logger.GetLogger()
-
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Metrics are always collected; the endpoint is optional
+	registry := metrics.NewRegistry()
+
+	// Write spans to stdout or a file
+	tracer, closeTracer, err := newTracer(cfg)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+	defer closeTracer()
+
+	// Validation rules are checked by both the validator stage and ingestion
+	rules, err := cfg.ValidationRules()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid validation rules")
+	}
+	decimal, err := cfg.DecimalOptions()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid decimal arithmetic options")
+	}
+
 	// Create pipeline with configuration
-	p, err := pipeline.NewPipeline(pipeline.Options{
+	opts := pipeline.Options{
 		NumWorkers:        cfg.Pipeline.NumWorkers,
 		AggregationWindow: cfg.Pipeline.AggregationWindow,
 		TasksPerSecond:    cfg.Pipeline.TasksPerSecond,
 		BurstSize:         cfg.Pipeline.BurstSize,
+		DisableRateLimit:  cfg.RateLimit.Disabled,
 		InputBufferSize:   cfg.BufferSizes.InputChannel,
 		ResultBufferSize:  cfg.BufferSizes.ResultChannel,
-	})
+		LaneWeights: map[models.Priority]int{
+			models.PriorityHigh:   cfg.Lanes.HighWeight,
+			models.PriorityNormal: cfg.Lanes.NormalWeight,
+			models.PriorityLow:    cfg.Lanes.LowWeight,
+		},
+		MaxLaneSkips:     cfg.Lanes.MaxSkips,
+		Metrics:          registry,
+		Tracer:           tracer,
+		ValidationRules:  rules,
+		Mode:             rules.Mode,
+		Decimal:          decimal,
+		ExplainErrors:    cfg.Arithmetic.ExplainErrors,
+		ProgramCacheSize: cfg.Arithmetic.ProgramCacheSize,
+	}
+	if cfg.RateLimit.Tenants.Enabled {
+		tenants := cfg.RateLimit.Tenants
+		opts.TenantRateLimit = &pipeline.TenantRateLimitOptions{
+			Default: pipeline.TenantLimit{
+				TasksPerSecond: tenants.Default.TasksPerSecond,
+				BurstSize:      tenants.Default.BurstSize,
+			},
+			Overrides:   make(map[string]pipeline.TenantLimit, len(tenants.Overrides)),
+			IdleTimeout: time.Duration(tenants.IdleTimeoutMs) * time.Millisecond,
+			MaxTenants:  tenants.MaxTenants,
+		}
+		for name, limit := range tenants.Overrides {
+			opts.TenantRateLimit.Overrides[name] = pipeline.TenantLimit{
+				TasksPerSecond: limit.TasksPerSecond,
+				BurstSize:      limit.BurstSize,
+			}
+		}
+	}
+	if cfg.FairQueue.Enabled {
+		opts.FairQueue = &pipeline.FairQueueOptions{
+			Weights:       cfg.FairQueue.Weights,
+			DefaultWeight: cfg.FairQueue.DefaultWeight,
+			Capacity:      cfg.FairQueue.Capacity,
+		}
+	}
+	if cfg.Autoscale.Enabled {
+		opts.Autoscale = &pipeline.AutoscaleOptions{
+			MinWorkers:          cfg.Autoscale.MinWorkers,
+			MaxWorkers:          cfg.Autoscale.MaxWorkers,
+			Interval:            time.Duration(cfg.Autoscale.IntervalMs) * time.Millisecond,
+			Cooldown:            time.Duration(cfg.Autoscale.CooldownMs) * time.Millisecond,
+			Step:                cfg.Autoscale.Step,
+			ScaleUpQueueRatio:   cfg.Autoscale.ScaleUpQueueRatio,
+			ScaleDownQueueRatio: cfg.Autoscale.ScaleDownQueueRatio,
+			TargetLatency:       time.Duration(cfg.Autoscale.TargetLatencyMs) * time.Millisecond,
+		}
+	}
+	p, err := pipeline.NewPipeline(opts)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create pipeline")
+		logger.Fatal().Err(err).Msg("Failed to create pipeline")
 	}
 
 	// Start pipeline
 	if err := p.Start(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Failed to start pipeline")
+		logger.Fatal().Err(err).Msg("Failed to start pipeline")
 	}
 
 	// Log configuration
-	log.Info().
+	logger.Info().
 		Int("workers", cfg.Pipeline.NumWorkers).
 		Int("aggregation_window", cfg.Pipeline.AggregationWindow).
 		Int("tasks_per_second", cfg.Pipeline.TasksPerSecond).
 		Int("burst_size", cfg.Pipeline.BurstSize).
+		Bool("rate_limit_disabled", cfg.RateLimit.Disabled).
 		Int("input_buffer", cfg.BufferSizes.InputChannel).
 		Int("result_buffer", cfg.BufferSizes.ResultChannel).
+		Bool("fair_queue", cfg.FairQueue.Enabled).
+		Bool("autoscale", cfg.Autoscale.Enabled).
+		Bool("tracing", cfg.Tracing.Enabled).
 		Bool("debug", cfg.Service.Debug).
 		Msg("Starting pipeline with configuration")
 
+	// Serve metrics
+	if cfg.Metrics.Enabled {
+		mux := http.NewServeMux()
+		mux.Handle(cfg.Metrics.Path, registry.Handler())
+		srv := &http.Server{
+			Addr:              cfg.Metrics.Address,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			logger.Info().Str("address", cfg.Metrics.Address).Str("path", cfg.Metrics.Path).Msg("Serving metrics")
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error().Err(err).Msg("Metrics server failed")
+			}
+		}()
+		defer srv.Close()
+	}
+
+	// Serve the admin API
+	var adminServer *admin.Server
+	if cfg.Admin.Enabled {
+		adminServer = admin.New(admin.Options{
+			Pipeline:     p,
+			Config:       cfg,
+			DrainTimeout: time.Duration(cfg.Admin.DrainTimeoutMs) * time.Millisecond,
+		})
+		srv := &http.Server{
+			Addr:              cfg.Admin.Address,
+			Handler:           adminServer.Handler(),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			logger.Info().Str("address", cfg.Admin.Address).Msg("Serving admin API")
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error().Err(err).Msg("Admin server failed")
+			}
+		}()
+		defer srv.Close()
+	}
+
+	// Serve the ingestion API
+	var hub *ingest.Hub
+	if cfg.Ingest.Enabled {
+		if cfg.Ingest.StreamResults {
+			hub = ingest.NewHub()
+		}
+		ingestServer := ingest.New(ingest.Options{
+			Pipeline:     p,
+			Hub:          hub,
+			MaxBatchSize: cfg.Ingest.MaxBatchSize,
+			MaxBodyBytes: cfg.Ingest.MaxBodyBytes,
+			Rules:        rules,
+			RetryAfter:   time.Duration(cfg.Ingest.RetryAfterMs) * time.Millisecond,
+		})
+		srv := &http.Server{
+			Addr:              cfg.Ingest.Address,
+			Handler:           ingestServer.Handler(),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			logger.Info().Str("address", cfg.Ingest.Address).Bool("stream_results", hub != nil).Msg("Serving ingestion API")
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error().Err(err).Msg("Ingestion server failed")
+			}
+		}()
+		// Let result streams deliver their end event before closing
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		}()
+	}
+
 	// Handle shutdown signals
 	go func() {
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 		sig := <-signals
-		log.Info().Str("signal", sig.String()).Msg("Shutdown signal received, stopping pipeline...")
+		logger.Info().Str("signal", sig.String()).Msg("Shutdown signal received, stopping pipeline...")
 		cancel()
 	}()
 
-	// Add tasks
+	// Reload runtime-adjustable settings on SIGHUP
 	go func() {
-		defer cancel() // Cancel context when done adding tasks
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		defer signal.Stop(reload)
 
-		for i := 0; i < 1000; i++ {
+		for {
 			select {
 			case <-ctx.Done():
 				return
-			default:
-				task := generateTask()
-				for {
-					err := p.AddTask(task)
-					if err == nil {
-						log.Debug().
-							Int("task_value", task.Value).
-							Int("operations", len(task.Operations)).
-							Msg("Task added successfully")
-						break
-					}
-					if err == pipeline.ErrRateLimitExceeded {
-						log.Debug().Msg("Rate limit exceeded, waiting to retry")
-						select {
-						case <-ctx.Done():
-							return
-						case <-time.After(time.Millisecond * 10):
-							continue
-						}
-					} else if err.Error() == "pipeline buffer full" {
-						log.Debug().Msg("Pipeline buffer full, waiting to retry")
-						select {
-						case <-ctx.Done():
-							return
-						case <-time.After(time.Millisecond * 10):
-							continue
-						}
-					} else {
-						log.Error().Err(err).Msg("Failed to add task")
-						return
-					}
+			case <-reload:
+				reloaded, err := loadConfig(*configFile)
+				if err != nil {
+					logger.Error().Err(err).Msg("Failed to reload configuration")
+					continue
+				}
+				if err := applyRateLimit(p, reloaded); err != nil {
+					logger.Error().Err(err).Msg("Failed to apply rate limit")
+					continue
 				}
+				if adminServer != nil {
+					adminServer.SetConfig(reloaded)
+				}
+				logger.Info().
+					Int("tasks_per_second", reloaded.Pipeline.TasksPerSecond).
+					Int("burst_size", reloaded.Pipeline.BurstSize).
+					Bool("rate_limit_disabled", reloaded.RateLimit.Disabled).
+					Msg("Configuration reloaded")
 			}
 		}
 	}()
 
+	// Generate tasks unless they arrive through the ingestion API
+	if !cfg.Ingest.Enabled {
+		go produceTasks(ctx, p)
+	}
+
 	// Collect results with timestamps
 	startTime := time.Now()
 	taskCount := 0
 	for result := range p.Results() {
+		if hub != nil {
+			hub.Publish(result)
+		}
 		taskCount++
 		elapsed := time.Since(startTime)
 		rate := float64(taskCount) / elapsed.Seconds()
 
 		if result.Error != nil {
-			log.Error().
+			event := logger.Error()
+			if result.Explanation != nil {
+				event = event.Interface("explanation", result.Explanation)
+			}
+			event.
 				Err(result.Error).
+				Str("code", string(models.CodeOf(result.Error))).
 				Int("task_count", taskCount).
 				Float64("current_rate", rate).
 				Msg("Error processing task")
 			continue
 		}
 
-		log.Info().
-			Int("result", result.Result).
+		logger.Info().
+			RawJSON("result", []byte(result.Exact())).
 			Int("task_count", taskCount).
 			Float64("current_rate", rate).
 			Dur("elapsed", elapsed).
 			Msg("Task processed successfully")
 	}
+
+	// End open result streams before the servers shut down
+	if hub != nil {
+		hub.Close()
+	}
+}
+
+// produceTasks adds generated tasks to the pipeline, retrying while it is
+// busy, and drains the pipeline once done
+func produceTasks(ctx context.Context, p pipeline.Pipeline) {
+	// Let queued tasks finish once done adding tasks; the results
+	// channel closes when the drain completes
+	defer func() {
+		if err := p.Drain(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error().Err(err).Msg("Failed to drain pipeline")
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			task := generateTask()
+			for {
+				err := p.AddTask(task)
+				if err == nil {
+					logger.Debug().
+						Int("task_value", task.Value).
+						Int("operations", len(task.Operations)).
+						Msg("Task added successfully")
+					break
+				}
+				if errors.Is(err, pipeline.ErrRateLimitExceeded) {
+					logger.Debug().Str("tenant", task.Tenant).Msg("Rate limit exceeded, waiting to retry")
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(time.Millisecond * 10):
+						continue
+					}
+				} else if errors.Is(err, pipeline.ErrBufferFull) {
+					logger.Debug().Msg("Pipeline buffer full, waiting to retry")
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(time.Millisecond * 10):
+						continue
+					}
+				} else if errors.Is(err, pipeline.ErrIntakePaused) {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(time.Millisecond * 100):
+						continue
+					}
+				} else if errors.Is(err, pipeline.ErrPipelineStopped) {
+					logger.Info().Msg("Pipeline stopped, no more tasks will be added")
+					return
+				} else {
+					logger.Error().Err(err).Msg("Failed to add task")
+					return
+				}
+			}
+		}
+	}
+}
+
+// loadConfig builds the configuration from defaults, environment variables
+// and the optional config file, in that order of precedence
+func loadConfig(path string) (*config.Config, error) {
+	cfg := config.DefaultConfig()
+
+	// Load from environment variables
+	cfg.LoadFromEnv()
+
+	// Load from config file if specified
+	if path != "" {
+		if err := cfg.LoadFromFile(path); err != nil {
+			return nil, fmt.Errorf("failed to load config file: %w", err)
+		}
+	}
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
 }
 
+// newTracer creates the span tracer described by cfg, or a nil tracer when
+// tracing is disabled. The returned function closes the output file.
+func newTracer(cfg *config.Config) (*tracing.Tracer, func(), error) {
+	if !cfg.Tracing.Enabled {
+		return nil, func() {}, nil
+	}
+
+	out, closeOut := os.Stdout, func() {}
+	if cfg.Tracing.Output != "stdout" {
+		f, err := os.OpenFile(cfg.Tracing.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open tracing output: %w", err)
+		}
+		out, closeOut = f, func() { f.Close() }
+	}
+
+	exp := tracing.NewJSONExporter(out, cfg.Tracing.ServiceName)
+	tracer := tracing.NewTracer(exp, func(err error) {
+		logger.Error().Err(err).Msg("Failed to export span")
+	})
+	return tracer, closeOut, nil
+}
+
+// applyRateLimit updates the admission rate limit of a running pipeline
+func applyRateLimit(p pipeline.Pipeline, cfg *config.Config) error {
+	if cfg.RateLimit.Disabled {
+		p.DisableRateLimit()
+		return nil
+	}
+	return p.SetRateLimit(cfg.Pipeline.TasksPerSecond, cfg.Pipeline.BurstSize)
+}
+
+// tenants are the simulated producers that generated tasks are attributed to
+var tenants = []string{"alpha", "beta", "gamma"}
+
 func generateTask() models.Task {
 	value := rand.Intn(100)
 	numberOfOperations := rand.Intn(5)
@@ -177,5 +447,7 @@ func generateTask() models.Task {
 	return models.Task{
 		Value:      value,
 		Operations: operations,
+		Tenant:     tenants[rand.Intn(len(tenants))],
+		Priority:   models.Priority(rand.Intn(int(models.PriorityTotalAmount))),
 	}
 }
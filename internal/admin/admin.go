@@ -0,0 +1,202 @@
+// Package admin serves the health, readiness, introspection and control
+// endpoints of a running pipeline over HTTP.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"concurrent-pipeline-processor/internal/config"
+	"concurrent-pipeline-processor/internal/logger"
+	"concurrent-pipeline-processor/internal/pipeline"
+)
+
+// defaultDrainTimeout bounds a drain started through the API when
+// Options.DrainTimeout is zero
+const defaultDrainTimeout = 30 * time.Second
+
+// Options configures a Server
+type Options struct {
+	// Pipeline is the pipeline the endpoints inspect and control
+	Pipeline pipeline.Pipeline
+	// Config is the configuration reported by /config
+	Config *config.Config
+	// DrainTimeout bounds a drain started through POST /drain
+	DrainTimeout time.Duration
+}
+
+// Server implements the admin endpoints
+type Server struct {
+	pipeline     pipeline.Pipeline
+	drainTimeout time.Duration
+
+	mu       sync.RWMutex
+	cfg      *config.Config
+	draining bool
+}
+
+// New creates an admin server for the given options
+func New(opts Options) *Server {
+	if opts.DrainTimeout <= 0 {
+		opts.DrainTimeout = defaultDrainTimeout
+	}
+	return &Server{
+		pipeline:     opts.Pipeline,
+		drainTimeout: opts.DrainTimeout,
+		cfg:          opts.Config,
+	}
+}
+
+// SetConfig replaces the configuration reported by /config, e.g. after a reload
+func (s *Server) SetConfig(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cfg = cfg
+}
+
+// Handler returns the HTTP handler serving the admin endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /readyz", s.handleReady)
+	mux.HandleFunc("GET /stats", s.handleStats)
+	mux.HandleFunc("GET /config", s.handleConfig)
+	mux.HandleFunc("POST /intake/pause", s.handlePauseIntake)
+	mux.HandleFunc("POST /intake/resume", s.handleResumeIntake)
+	mux.HandleFunc("POST /pause", s.handlePause)
+	mux.HandleFunc("POST /resume", s.handleResume)
+	mux.HandleFunc("POST /log-level", s.handleLogLevel)
+	mux.HandleFunc("POST /drain", s.handleDrain)
+	return mux
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, statusResponse{Status: "ok"})
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, _ *http.Request) {
+	if !s.pipeline.Ready() {
+		writeJSON(w, http.StatusServiceUnavailable, statusResponse{Status: "not ready"})
+		return
+	}
+	writeJSON(w, http.StatusOK, statusResponse{Status: "ready"})
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, newStatsResponse(s.pipeline))
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	cfg := *s.cfg
+	s.mu.RUnlock()
+
+	// Report the settings that can change at runtime as they are now
+	cfg.Service.LogLevel = logger.Level()
+	tps, burst, enabled := s.pipeline.RateLimit()
+	cfg.Pipeline.TasksPerSecond = tps
+	cfg.Pipeline.BurstSize = burst
+	cfg.RateLimit.Disabled = !enabled
+
+	writeJSON(w, http.StatusOK, cfg)
+}
+
+func (s *Server) handlePauseIntake(w http.ResponseWriter, _ *http.Request) {
+	s.pipeline.PauseIntake()
+	logger.Info().Msg("Intake paused")
+	writeJSON(w, http.StatusOK, intakeResponse{Paused: true})
+}
+
+func (s *Server) handleResumeIntake(w http.ResponseWriter, _ *http.Request) {
+	s.pipeline.ResumeIntake()
+	logger.Info().Msg("Intake resumed")
+	writeJSON(w, http.StatusOK, intakeResponse{Paused: false})
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, _ *http.Request) {
+	if err := s.pipeline.Pause(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	logger.Info().Msg("Processing paused")
+	writeJSON(w, http.StatusOK, stateResponse{State: s.pipeline.State().String()})
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, _ *http.Request) {
+	s.pipeline.Resume()
+	logger.Info().Msg("Processing resumed")
+	writeJSON(w, http.StatusOK, stateResponse{State: s.pipeline.State().String()})
+}
+
+func (s *Server) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req logLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := logger.SetLevel(req.Level); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	logger.Info().Str("level", req.Level).Msg("Log level changed")
+	writeJSON(w, http.StatusOK, logLevelRequest{Level: logger.Level()})
+}
+
+func (s *Server) handleDrain(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	started := !s.draining
+	s.draining = true
+	s.mu.Unlock()
+
+	if started {
+		go s.drain()
+	}
+	writeJSON(w, http.StatusAccepted, statusResponse{Status: "draining"})
+}
+
+// drain drains the pipeline, giving up after the drain timeout
+func (s *Server) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+	defer cancel()
+
+	logger.Info().Dur("timeout", s.drainTimeout).Msg("Draining pipeline")
+	if err := s.pipeline.Drain(ctx); err != nil {
+		logger.Error().Err(err).Msg("Failed to drain pipeline")
+		return
+	}
+	logger.Info().Msg("Pipeline drained")
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+type intakeResponse struct {
+	Paused bool `json:"paused"`
+}
+
+type stateResponse struct {
+	State string `json:"state"`
+}
+
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
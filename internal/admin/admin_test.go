@@ -0,0 +1,208 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/config"
+	"concurrent-pipeline-processor/internal/logger"
+	"concurrent-pipeline-processor/internal/pipeline"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func newTestServer(t *testing.T) (pipeline.Pipeline, http.Handler) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	p, err := pipeline.NewPipeline(pipeline.Options{
+		NumWorkers:        2,
+		AggregationWindow: 2,
+		TasksPerSecond:    100,
+		BurstSize:         200,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	srv := New(Options{Pipeline: p, Config: config.DefaultConfig(), DrainTimeout: 2 * time.Second})
+	return p, srv.Handler()
+}
+
+func do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestEndpoints(t *testing.T) {
+	_, h := newTestServer(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"health", http.MethodGet, "/healthz", "", http.StatusOK, `"status":"ok"`},
+		{"ready", http.MethodGet, "/readyz", "", http.StatusOK, `"status":"ready"`},
+		{"stats", http.MethodGet, "/stats", "", http.StatusOK, `"accepted":0`},
+		{"config", http.MethodGet, "/config", "", http.StatusOK, `"aggregation_window":50`},
+		{"wrong method", http.MethodPost, "/healthz", "", http.StatusMethodNotAllowed, ""},
+		{"log level", http.MethodPost, "/log-level", `{"level":"warn"}`, http.StatusOK, `"level":"warn"`},
+		{"invalid log level", http.MethodPost, "/log-level", `{"level":"loud"}`, http.StatusBadRequest, `"error"`},
+		{"malformed log level", http.MethodPost, "/log-level", `{`, http.StatusBadRequest, `"error"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(h, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %q, got %s", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+
+	if got := logger.Level(); got != "warn" {
+		t.Errorf("Expected log level warn, got %s", got)
+	}
+}
+
+func TestConfigReportsRuntimeValues(t *testing.T) {
+	p, h := newTestServer(t)
+
+	if err := p.SetRateLimit(7, 9); err != nil {
+		t.Fatalf("SetRateLimit() error = %v", err)
+	}
+
+	var cfg config.Config
+	if err := json.NewDecoder(do(h, http.MethodGet, "/config", "").Body).Decode(&cfg); err != nil {
+		t.Fatalf("Failed to decode config: %v", err)
+	}
+	if cfg.Pipeline.TasksPerSecond != 7 || cfg.Pipeline.BurstSize != 9 {
+		t.Errorf("Expected rate limit 7/9, got %d/%d", cfg.Pipeline.TasksPerSecond, cfg.Pipeline.BurstSize)
+	}
+}
+
+func TestIntakeControl(t *testing.T) {
+	p, h := newTestServer(t)
+	task := models.Task{Value: 1, Operations: []models.Operation{}}
+
+	if rec := do(h, http.MethodPost, "/intake/pause", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if err := p.AddTask(task); !errors.Is(err, pipeline.ErrIntakePaused) {
+		t.Errorf("Expected ErrIntakePaused, got %v", err)
+	}
+
+	var stats statsResponse
+	if err := json.NewDecoder(do(h, http.MethodGet, "/stats", "").Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode stats: %v", err)
+	}
+	if !stats.IntakePaused {
+		t.Error("Expected stats to report paused intake")
+	}
+	if stats.Rejected["intake_paused"] != 1 {
+		t.Errorf("Expected 1 intake_paused rejection, got %v", stats.Rejected)
+	}
+
+	if rec := do(h, http.MethodPost, "/intake/resume", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if err := p.AddTask(task); err != nil {
+		t.Errorf("Failed to add task after resume: %v", err)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	p, h := newTestServer(t)
+
+	for i := 0; i < 3; i++ {
+		if err := p.AddTask(models.Task{Value: 1, Operations: []models.Operation{}}); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+
+	if rec := do(h, http.MethodPost, "/drain", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", rec.Code)
+	}
+	if rec := do(h, http.MethodPost, "/drain", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected repeated drain to return 202, got %d", rec.Code)
+	}
+
+	sum := 0
+	timeout := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case result, ok := <-p.Results():
+			if !ok {
+				done = true
+				break
+			}
+			sum += result.Result
+		case <-timeout:
+			t.Fatal("Timeout waiting for drain to close results")
+		}
+	}
+	if sum != 3 {
+		t.Errorf("Expected drained results to sum to 3, got %d", sum)
+	}
+
+	if rec := do(h, http.MethodGet, "/readyz", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 after drain, got %d", rec.Code)
+	}
+}
+
+func TestPauseProcessing(t *testing.T) {
+	p, h := newTestServer(t)
+
+	rec := do(h, http.MethodPost, "/pause", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"state":"paused"`) {
+		t.Errorf("Expected paused state, got %s", rec.Body.String())
+	}
+	if got := p.State(); got != pipeline.StatePaused {
+		t.Errorf("Expected pipeline to be paused, got %s", got)
+	}
+
+	var stats statsResponse
+	if err := json.NewDecoder(do(h, http.MethodGet, "/stats", "").Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode stats: %v", err)
+	}
+	if stats.State != "paused" {
+		t.Errorf("Expected stats state paused, got %s", stats.State)
+	}
+
+	if rec := do(h, http.MethodPost, "/resume", ""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if got := p.State(); got != pipeline.StateRunning {
+		t.Errorf("Expected pipeline to be running, got %s", got)
+	}
+
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if rec := do(h, http.MethodPost, "/pause", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 pausing a stopped pipeline, got %d", rec.Code)
+	}
+}
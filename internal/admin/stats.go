@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"concurrent-pipeline-processor/internal/pipeline"
+)
+
+// statsResponse is the JSON form of pipeline.Stats served by /stats
+type statsResponse struct {
+	UptimeSeconds           float64           `json:"uptime_seconds"`
+	State                   string            `json:"state"`
+	Ready                   bool              `json:"ready"`
+	IntakePaused            bool              `json:"intake_paused"`
+	Accepted                uint64            `json:"accepted"`
+	Rejected                map[string]uint64 `json:"rejected"`
+	Validated               uint64            `json:"validated"`
+	Processed               uint64            `json:"processed"`
+	Failed                  map[string]uint64 `json:"failed"`
+	Aggregated              uint64            `json:"aggregated"`
+	Panics                  uint64            `json:"panics"`
+	Queues                  queuesResponse    `json:"queues"`
+	Workers                 int               `json:"workers"`
+	RateLimitEnabled        bool              `json:"rate_limit_enabled"`
+	RateLimitTokens         float64           `json:"rate_limit_tokens"`
+	Throughput              float64           `json:"throughput_per_second"`
+	ThroughputWindowSeconds float64           `json:"throughput_window_seconds"`
+}
+
+type queuesResponse struct {
+	Lanes     map[string]int `json:"lanes"`
+	Validated int            `json:"validated"`
+	Processed int            `json:"processed"`
+	Output    int            `json:"output"`
+}
+
+func newStatsResponse(p pipeline.Pipeline) statsResponse {
+	stats := p.Stats()
+
+	lanes := make(map[string]int, len(stats.Queues.Lanes))
+	for priority, depth := range stats.Queues.Lanes {
+		lanes[priority.String()] = depth
+	}
+
+	return statsResponse{
+		UptimeSeconds: stats.Uptime.Seconds(),
+		State:         p.State().String(),
+		Ready:         p.Ready(),
+		IntakePaused:  p.IntakePaused(),
+		Accepted:      stats.Accepted,
+		Rejected:      stats.Rejected,
+		Validated:     stats.Validated,
+		Processed:     stats.Processed,
+		Failed:        stats.Failed,
+		Aggregated:    stats.Aggregated,
+		Panics:        stats.Panics,
+		Queues: queuesResponse{
+			Lanes:     lanes,
+			Validated: stats.Queues.Validated,
+			Processed: stats.Queues.Processed,
+			Output:    stats.Queues.Output,
+		},
+		Workers:                 stats.Workers,
+		RateLimitEnabled:        stats.RateLimitEnabled,
+		RateLimitTokens:         stats.RateLimitTokens,
+		Throughput:              stats.Throughput,
+		ThroughputWindowSeconds: stats.ThroughputWindow.Seconds(),
+	}
+}
@@ -5,8 +5,18 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/pkg/models"
 )
 
+// TenantLimit holds the rate limit for a single tenant
+type TenantLimit struct {
+	TasksPerSecond int `json:"tasks_per_second"`
+	BurstSize      int `json:"burst_size"`
+}
+
 // Config holds all configuration for the service
 type Config struct {
 	// Pipeline configuration
@@ -30,6 +40,104 @@ type Config struct {
 		InputChannel  int `json:"input_channel"`
 		ResultChannel int `json:"result_channel"`
 	} `json:"buffer_sizes"`
+
+	// Priority lane configuration
+	Lanes struct {
+		HighWeight   int `json:"high_weight"`
+		NormalWeight int `json:"normal_weight"`
+		LowWeight    int `json:"low_weight"`
+		MaxSkips     int `json:"max_skips"`
+	} `json:"lanes"`
+
+	// Rate limit configuration
+	RateLimit struct {
+		Disabled bool `json:"disabled"`
+
+		// Per-tenant limits keyed by the task's tenant
+		Tenants struct {
+			Enabled       bool                   `json:"enabled"`
+			Default       TenantLimit            `json:"default"`
+			Overrides     map[string]TenantLimit `json:"overrides"`
+			IdleTimeoutMs int                    `json:"idle_timeout_ms"`
+			MaxTenants    int                    `json:"max_tenants"`
+		} `json:"tenants"`
+	} `json:"rate_limit"`
+
+	// Fair queue configuration
+	FairQueue struct {
+		Enabled       bool           `json:"enabled"`
+		DefaultWeight int            `json:"default_weight"`
+		Capacity      int            `json:"capacity"`
+		Weights       map[string]int `json:"weights"`
+	} `json:"fair_queue"`
+
+	// Validation rules applied to every task; unset bounds are not checked
+	Validation struct {
+		MaxOperations          int      `json:"max_operations"`
+		MinValue               *int     `json:"min_value"`
+		MaxValue               *int     `json:"max_value"`
+		MinOperand             *int     `json:"min_operand"`
+		MaxOperand             *int     `json:"max_operand"`
+		AllowedOperators       []string `json:"allowed_operators"`
+		RejectEmptyOperations  bool     `json:"reject_empty_operations"`
+		RejectPossibleOverflow bool     `json:"reject_possible_overflow"`
+	} `json:"validation"`
+
+	// Arithmetic configuration
+	Arithmetic struct {
+		Mode     string `json:"mode"`
+		Scale    int    `json:"scale"`
+		Rounding string `json:"rounding"`
+		// ExplainErrors attaches the intermediate values to failed results
+		ExplainErrors bool `json:"explain_errors"`
+		// ProgramCacheSize caches this many compiled operation lists when
+		// greater than 0
+		ProgramCacheSize int `json:"program_cache_size"`
+	} `json:"arithmetic"`
+
+	// Metrics configuration
+	Metrics struct {
+		Enabled bool   `json:"enabled"`
+		Address string `json:"address"`
+		Path    string `json:"path"`
+	} `json:"metrics"`
+
+	// HTTP ingestion configuration
+	Ingest struct {
+		Enabled       bool   `json:"enabled"`
+		Address       string `json:"address"`
+		MaxBatchSize  int    `json:"max_batch_size"`
+		MaxBodyBytes  int64  `json:"max_body_bytes"`
+		RetryAfterMs  int    `json:"retry_after_ms"`
+		StreamResults bool   `json:"stream_results"`
+	} `json:"ingest"`
+
+	// Admin server configuration
+	Admin struct {
+		Enabled        bool   `json:"enabled"`
+		Address        string `json:"address"`
+		DrainTimeoutMs int    `json:"drain_timeout_ms"`
+	} `json:"admin"`
+
+	// Tracing configuration
+	Tracing struct {
+		Enabled     bool   `json:"enabled"`
+		Output      string `json:"output"`
+		ServiceName string `json:"service_name"`
+	} `json:"tracing"`
+
+	// Autoscale configuration
+	Autoscale struct {
+		Enabled             bool    `json:"enabled"`
+		MinWorkers          int     `json:"min_workers"`
+		MaxWorkers          int     `json:"max_workers"`
+		IntervalMs          int     `json:"interval_ms"`
+		CooldownMs          int     `json:"cooldown_ms"`
+		Step                int     `json:"step"`
+		ScaleUpQueueRatio   float64 `json:"scale_up_queue_ratio"`
+		ScaleDownQueueRatio float64 `json:"scale_down_queue_ratio"`
+		TargetLatencyMs     int     `json:"target_latency_ms"`
+	} `json:"autoscale"`
 }
 
 // DefaultConfig returns the default configuration
@@ -52,6 +160,63 @@ func DefaultConfig() *Config {
 	cfg.BufferSizes.InputChannel = 1000
 	cfg.BufferSizes.ResultChannel = 1000
 
+	// Lane defaults
+	cfg.Lanes.HighWeight = 8
+	cfg.Lanes.NormalWeight = 4
+	cfg.Lanes.LowWeight = 1
+	cfg.Lanes.MaxSkips = 32
+
+	// Rate limit defaults
+	cfg.RateLimit.Disabled = false
+	cfg.RateLimit.Tenants.Enabled = false
+	cfg.RateLimit.Tenants.Default = TenantLimit{TasksPerSecond: 10, BurstSize: 20}
+	cfg.RateLimit.Tenants.IdleTimeoutMs = 300000
+	cfg.RateLimit.Tenants.MaxTenants = 10000
+
+	// Fair queue defaults
+	cfg.FairQueue.Enabled = false
+	cfg.FairQueue.DefaultWeight = 1
+	cfg.FairQueue.Capacity = 1000
+
+	// Arithmetic defaults
+	cfg.Arithmetic.Mode = "int"
+	cfg.Arithmetic.Scale = 2
+	cfg.Arithmetic.Rounding = "half_even"
+
+	// Metrics defaults
+	cfg.Metrics.Enabled = false
+	cfg.Metrics.Address = "127.0.0.1:9090"
+	cfg.Metrics.Path = "/metrics"
+
+	// Ingest defaults
+	cfg.Ingest.Enabled = false
+	cfg.Ingest.Address = "127.0.0.1:8080"
+	cfg.Ingest.MaxBatchSize = 1000
+	cfg.Ingest.MaxBodyBytes = 1 << 20
+	cfg.Ingest.RetryAfterMs = 1000
+	cfg.Ingest.StreamResults = false
+
+	// Admin defaults
+	cfg.Admin.Enabled = false
+	cfg.Admin.Address = "127.0.0.1:8081"
+	cfg.Admin.DrainTimeoutMs = 30000
+
+	// Tracing defaults
+	cfg.Tracing.Enabled = false
+	cfg.Tracing.Output = "stdout"
+	cfg.Tracing.ServiceName = "concurrent-pipeline-processor"
+
+	// Autoscale defaults
+	cfg.Autoscale.Enabled = false
+	cfg.Autoscale.MinWorkers = 1
+	cfg.Autoscale.MaxWorkers = 50
+	cfg.Autoscale.IntervalMs = 1000
+	cfg.Autoscale.CooldownMs = 5000
+	cfg.Autoscale.Step = 1
+	cfg.Autoscale.ScaleUpQueueRatio = 0.75
+	cfg.Autoscale.ScaleDownQueueRatio = 0.25
+	cfg.Autoscale.TargetLatencyMs = 0
+
 	return cfg
 }
 
@@ -104,6 +269,136 @@ func (c *Config) LoadFromEnv() {
 			c.BufferSizes.ResultChannel = i
 		}
 	}
+
+	// Lane config
+	setIntFromEnv("LANES_HIGH_WEIGHT", &c.Lanes.HighWeight)
+	setIntFromEnv("LANES_NORMAL_WEIGHT", &c.Lanes.NormalWeight)
+	setIntFromEnv("LANES_LOW_WEIGHT", &c.Lanes.LowWeight)
+	setIntFromEnv("LANES_MAX_SKIPS", &c.Lanes.MaxSkips)
+
+	// Rate limit config
+	setBoolFromEnv("RATE_LIMIT_DISABLED", &c.RateLimit.Disabled)
+	setBoolFromEnv("RATE_LIMIT_TENANTS_ENABLED", &c.RateLimit.Tenants.Enabled)
+	setIntFromEnv("RATE_LIMIT_TENANT_TASKS_PER_SECOND", &c.RateLimit.Tenants.Default.TasksPerSecond)
+	setIntFromEnv("RATE_LIMIT_TENANT_BURST_SIZE", &c.RateLimit.Tenants.Default.BurstSize)
+	setIntFromEnv("RATE_LIMIT_TENANT_IDLE_TIMEOUT_MS", &c.RateLimit.Tenants.IdleTimeoutMs)
+	setIntFromEnv("RATE_LIMIT_MAX_TENANTS", &c.RateLimit.Tenants.MaxTenants)
+
+	// Fair queue config
+	setBoolFromEnv("FAIR_QUEUE_ENABLED", &c.FairQueue.Enabled)
+	setIntFromEnv("FAIR_QUEUE_DEFAULT_WEIGHT", &c.FairQueue.DefaultWeight)
+	setIntFromEnv("FAIR_QUEUE_CAPACITY", &c.FairQueue.Capacity)
+
+	// Validation config
+	setIntFromEnv("VALIDATION_MAX_OPERATIONS", &c.Validation.MaxOperations)
+	setIntPtrFromEnv("VALIDATION_MIN_VALUE", &c.Validation.MinValue)
+	setIntPtrFromEnv("VALIDATION_MAX_VALUE", &c.Validation.MaxValue)
+	setIntPtrFromEnv("VALIDATION_MIN_OPERAND", &c.Validation.MinOperand)
+	setIntPtrFromEnv("VALIDATION_MAX_OPERAND", &c.Validation.MaxOperand)
+	if v := os.Getenv("VALIDATION_ALLOWED_OPERATORS"); v != "" {
+		c.Validation.AllowedOperators = strings.Split(v, ",")
+	}
+	setBoolFromEnv("VALIDATION_REJECT_EMPTY_OPERATIONS", &c.Validation.RejectEmptyOperations)
+	setBoolFromEnv("VALIDATION_REJECT_POSSIBLE_OVERFLOW", &c.Validation.RejectPossibleOverflow)
+
+	// Arithmetic config
+	if v := os.Getenv("ARITHMETIC_MODE"); v != "" {
+		c.Arithmetic.Mode = v
+	}
+	setIntFromEnv("ARITHMETIC_SCALE", &c.Arithmetic.Scale)
+	if v := os.Getenv("ARITHMETIC_ROUNDING"); v != "" {
+		c.Arithmetic.Rounding = v
+	}
+	setBoolFromEnv("ARITHMETIC_EXPLAIN_ERRORS", &c.Arithmetic.ExplainErrors)
+	setIntFromEnv("ARITHMETIC_PROGRAM_CACHE_SIZE", &c.Arithmetic.ProgramCacheSize)
+
+	// Metrics config
+	setBoolFromEnv("METRICS_ENABLED", &c.Metrics.Enabled)
+	if v := os.Getenv("METRICS_ADDRESS"); v != "" {
+		c.Metrics.Address = v
+	}
+	if v := os.Getenv("METRICS_PATH"); v != "" {
+		c.Metrics.Path = v
+	}
+
+	// Ingest config
+	setBoolFromEnv("INGEST_ENABLED", &c.Ingest.Enabled)
+	if v := os.Getenv("INGEST_ADDRESS"); v != "" {
+		c.Ingest.Address = v
+	}
+	setIntFromEnv("INGEST_MAX_BATCH_SIZE", &c.Ingest.MaxBatchSize)
+	if v := os.Getenv("INGEST_MAX_BODY_BYTES"); v != "" {
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			c.Ingest.MaxBodyBytes = i
+		}
+	}
+	setIntFromEnv("INGEST_RETRY_AFTER_MS", &c.Ingest.RetryAfterMs)
+	setBoolFromEnv("INGEST_STREAM_RESULTS", &c.Ingest.StreamResults)
+
+	// Admin config
+	setBoolFromEnv("ADMIN_ENABLED", &c.Admin.Enabled)
+	if v := os.Getenv("ADMIN_ADDRESS"); v != "" {
+		c.Admin.Address = v
+	}
+	setIntFromEnv("ADMIN_DRAIN_TIMEOUT_MS", &c.Admin.DrainTimeoutMs)
+
+	// Tracing config
+	setBoolFromEnv("TRACING_ENABLED", &c.Tracing.Enabled)
+	if v := os.Getenv("TRACING_OUTPUT"); v != "" {
+		c.Tracing.Output = v
+	}
+	if v := os.Getenv("TRACING_SERVICE_NAME"); v != "" {
+		c.Tracing.ServiceName = v
+	}
+
+	// Autoscale config
+	setBoolFromEnv("AUTOSCALE_ENABLED", &c.Autoscale.Enabled)
+	setIntFromEnv("AUTOSCALE_MIN_WORKERS", &c.Autoscale.MinWorkers)
+	setIntFromEnv("AUTOSCALE_MAX_WORKERS", &c.Autoscale.MaxWorkers)
+	setIntFromEnv("AUTOSCALE_INTERVAL_MS", &c.Autoscale.IntervalMs)
+	setIntFromEnv("AUTOSCALE_COOLDOWN_MS", &c.Autoscale.CooldownMs)
+	setIntFromEnv("AUTOSCALE_STEP", &c.Autoscale.Step)
+	setFloatFromEnv("AUTOSCALE_SCALE_UP_QUEUE_RATIO", &c.Autoscale.ScaleUpQueueRatio)
+	setFloatFromEnv("AUTOSCALE_SCALE_DOWN_QUEUE_RATIO", &c.Autoscale.ScaleDownQueueRatio)
+	setIntFromEnv("AUTOSCALE_TARGET_LATENCY_MS", &c.Autoscale.TargetLatencyMs)
+}
+
+// setIntFromEnv overwrites dst with the integer value of the environment
+// variable key, leaving it unchanged when unset or malformed
+func setIntFromEnv(key string, dst *int) {
+	if v := os.Getenv(key); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			*dst = i
+		}
+	}
+}
+
+// setIntPtrFromEnv sets dst to the integer value of the environment variable
+// key, leaving it unchanged when unset or malformed
+func setIntPtrFromEnv(key string, dst **int) {
+	if v := os.Getenv(key); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			*dst = &i
+		}
+	}
+}
+
+// setFloatFromEnv overwrites dst with the float value of the environment
+// variable key, leaving it unchanged when unset or malformed
+func setFloatFromEnv(key string, dst *float64) {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			*dst = f
+		}
+	}
+}
+
+// setBoolFromEnv overwrites dst with whether the environment variable key
+// equals "true", leaving it unchanged when unset
+func setBoolFromEnv(key string, dst *bool) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v == "true"
+	}
 }
 
 // LoadFromFile loads configuration from a JSON file
@@ -140,5 +435,140 @@ func (c *Config) Validate() error {
 	if c.BufferSizes.ResultChannel <= 0 {
 		return fmt.Errorf("result channel buffer size must be greater than 0")
 	}
+	if c.Lanes.HighWeight <= 0 || c.Lanes.NormalWeight <= 0 || c.Lanes.LowWeight <= 0 {
+		return fmt.Errorf("lane weights must be greater than 0")
+	}
+	if c.RateLimit.Tenants.Enabled {
+		if c.RateLimit.Tenants.Default.TasksPerSecond <= 0 {
+			return fmt.Errorf("default tenant tasks per second must be greater than 0")
+		}
+		for tenant, limit := range c.RateLimit.Tenants.Overrides {
+			if limit.TasksPerSecond <= 0 {
+				return fmt.Errorf("tasks per second for tenant %q must be greater than 0", tenant)
+			}
+		}
+	}
+	if c.FairQueue.Enabled {
+		if c.FairQueue.DefaultWeight <= 0 {
+			return fmt.Errorf("fair queue default weight must be greater than 0")
+		}
+		if c.FairQueue.Capacity <= 0 {
+			return fmt.Errorf("fair queue capacity must be greater than 0")
+		}
+		for tenant, w := range c.FairQueue.Weights {
+			if w <= 0 {
+				return fmt.Errorf("fair queue weight for tenant %q must be greater than 0", tenant)
+			}
+		}
+	}
+	if _, err := c.ArithmeticMode(); err != nil {
+		return err
+	}
+	if _, err := c.DecimalOptions(); err != nil {
+		return err
+	}
+	if c.Arithmetic.ProgramCacheSize < 0 {
+		return fmt.Errorf("program cache size must not be negative")
+	}
+	if _, err := c.ValidationRules(); err != nil {
+		return err
+	}
+	if c.Metrics.Enabled {
+		if c.Metrics.Address == "" {
+			return fmt.Errorf("metrics address must not be empty")
+		}
+		if !strings.HasPrefix(c.Metrics.Path, "/") {
+			return fmt.Errorf("metrics path must start with /")
+		}
+	}
+	if c.Ingest.Enabled {
+		if c.Ingest.Address == "" {
+			return fmt.Errorf("ingest address must not be empty")
+		}
+		if c.Ingest.MaxBatchSize <= 0 {
+			return fmt.Errorf("ingest max batch size must be greater than 0")
+		}
+		if c.Ingest.MaxBodyBytes <= 0 {
+			return fmt.Errorf("ingest max body bytes must be greater than 0")
+		}
+		if c.Ingest.RetryAfterMs <= 0 {
+			return fmt.Errorf("ingest retry after must be greater than 0")
+		}
+	}
+	if c.Admin.Enabled {
+		if c.Admin.Address == "" {
+			return fmt.Errorf("admin address must not be empty")
+		}
+		if c.Admin.DrainTimeoutMs <= 0 {
+			return fmt.Errorf("admin drain timeout must be greater than 0")
+		}
+	}
+	if c.Tracing.Enabled && c.Tracing.Output == "" {
+		return fmt.Errorf("tracing output must not be empty")
+	}
+	if c.Autoscale.Enabled {
+		if c.Autoscale.MinWorkers <= 0 {
+			return fmt.Errorf("autoscale min workers must be greater than 0")
+		}
+		if c.Autoscale.MaxWorkers < c.Autoscale.MinWorkers {
+			return fmt.Errorf("autoscale max workers must be at least min workers")
+		}
+		if c.Autoscale.ScaleDownQueueRatio >= c.Autoscale.ScaleUpQueueRatio {
+			return fmt.Errorf("autoscale scale down ratio must be below scale up ratio")
+		}
+	}
 	return nil
 }
+
+// ValidationRules converts the validation section to the rules checked by the
+// validator stage
+func (c *Config) ValidationRules() (processor.Rules, error) {
+	v := c.Validation
+	rules := processor.Rules{
+		MaxOperations:          v.MaxOperations,
+		MinValue:               v.MinValue,
+		MaxValue:               v.MaxValue,
+		MinOperand:             v.MinOperand,
+		MaxOperand:             v.MaxOperand,
+		RejectEmptyOperations:  v.RejectEmptyOperations,
+		RejectPossibleOverflow: v.RejectPossibleOverflow,
+	}
+	mode, err := c.ArithmeticMode()
+	if err != nil {
+		return processor.Rules{}, err
+	}
+	rules.Mode = mode
+	for _, name := range v.AllowedOperators {
+		op, err := models.ParseOperator(strings.TrimSpace(name))
+		if err != nil {
+			return processor.Rules{}, fmt.Errorf("validation allowed operators: %w", err)
+		}
+		rules.AllowedOperators = append(rules.AllowedOperators, op)
+	}
+	if err := rules.Validate(); err != nil {
+		return processor.Rules{}, fmt.Errorf("validation rules: %w", err)
+	}
+	return rules, nil
+}
+
+// ArithmeticMode returns the mode tasks are processed and aggregated with
+func (c *Config) ArithmeticMode() (processor.Mode, error) {
+	mode, err := processor.ParseMode(c.Arithmetic.Mode)
+	if err != nil {
+		return 0, fmt.Errorf("arithmetic mode: %w", err)
+	}
+	return mode, nil
+}
+
+// DecimalOptions returns the scale and rounding of the decimal arithmetic mode
+func (c *Config) DecimalOptions() (processor.DecimalOptions, error) {
+	rounding, err := processor.ParseRounding(c.Arithmetic.Rounding)
+	if err != nil {
+		return processor.DecimalOptions{}, fmt.Errorf("arithmetic rounding: %w", err)
+	}
+	opts := processor.DecimalOptions{Scale: c.Arithmetic.Scale, Rounding: rounding}
+	if err := opts.Validate(); err != nil {
+		return processor.DecimalOptions{}, fmt.Errorf("arithmetic: %w", err)
+	}
+	return opts, nil
+}
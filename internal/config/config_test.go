@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"testing"
+
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/pkg/models"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -237,3 +240,406 @@ func TestLoadFromFile(t *testing.T) {
 		t.Error("Expected error when loading from non-existent file")
 	}
 }
+
+func TestAutoscaleConfig(t *testing.T) {
+	t.Run("loads from env", func(t *testing.T) {
+		envVars := map[string]string{
+			"AUTOSCALE_ENABLED":                "true",
+			"AUTOSCALE_MIN_WORKERS":            "2",
+			"AUTOSCALE_MAX_WORKERS":            "20",
+			"AUTOSCALE_INTERVAL_MS":            "500",
+			"AUTOSCALE_SCALE_UP_QUEUE_RATIO":   "0.9",
+			"AUTOSCALE_SCALE_DOWN_QUEUE_RATIO": "0.1",
+		}
+
+		for k, v := range envVars {
+			os.Setenv(k, v)
+			defer os.Unsetenv(k)
+		}
+
+		cfg := DefaultConfig()
+		cfg.LoadFromEnv()
+
+		if !cfg.Autoscale.Enabled {
+			t.Error("Expected Autoscale.Enabled=true")
+		}
+		if cfg.Autoscale.MinWorkers != 2 {
+			t.Errorf("Expected MinWorkers=2, got %d", cfg.Autoscale.MinWorkers)
+		}
+		if cfg.Autoscale.MaxWorkers != 20 {
+			t.Errorf("Expected MaxWorkers=20, got %d", cfg.Autoscale.MaxWorkers)
+		}
+		if cfg.Autoscale.IntervalMs != 500 {
+			t.Errorf("Expected IntervalMs=500, got %d", cfg.Autoscale.IntervalMs)
+		}
+		if cfg.Autoscale.ScaleUpQueueRatio != 0.9 {
+			t.Errorf("Expected ScaleUpQueueRatio=0.9, got %v", cfg.Autoscale.ScaleUpQueueRatio)
+		}
+		if cfg.Autoscale.ScaleDownQueueRatio != 0.1 {
+			t.Errorf("Expected ScaleDownQueueRatio=0.1, got %v", cfg.Autoscale.ScaleDownQueueRatio)
+		}
+	})
+
+	t.Run("validates bounds when enabled", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Autoscale.Enabled = true
+		cfg.Autoscale.MinWorkers = 10
+		cfg.Autoscale.MaxWorkers = 5
+		if err := cfg.Validate(); err == nil {
+			t.Error("Expected error for max workers below min workers")
+		}
+
+		cfg.Autoscale.Enabled = false
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected disabled autoscale to be ignored, got %v", err)
+		}
+	})
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.RateLimit.Disabled {
+		t.Error("Expected rate limiting to be enabled by default")
+	}
+
+	os.Setenv("RATE_LIMIT_DISABLED", "true")
+	defer os.Unsetenv("RATE_LIMIT_DISABLED")
+
+	cfg.LoadFromEnv()
+	if !cfg.RateLimit.Disabled {
+		t.Error("Expected RateLimit.Disabled=true")
+	}
+}
+
+func TestTenantRateLimitConfig(t *testing.T) {
+	content := []byte(`{
+		"rate_limit": {
+			"tenants": {
+				"enabled": true,
+				"default": {"tasks_per_second": 5, "burst_size": 10},
+				"overrides": {
+					"batch": {"tasks_per_second": 1, "burst_size": 1},
+					"realtime": {"tasks_per_second": 50, "burst_size": 100}
+				}
+			}
+		}
+	}`)
+
+	tmpfile, err := os.CreateTemp("", "config-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	if err := cfg.LoadFromFile(tmpfile.Name()); err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+
+	tenants := cfg.RateLimit.Tenants
+	if !tenants.Enabled {
+		t.Error("Expected tenant rate limiting to be enabled")
+	}
+	if tenants.Default.TasksPerSecond != 5 || tenants.Default.BurstSize != 10 {
+		t.Errorf("Expected default limit 5/10, got %+v", tenants.Default)
+	}
+	if got := tenants.Overrides["realtime"].TasksPerSecond; got != 50 {
+		t.Errorf("Expected realtime TasksPerSecond=50, got %d", got)
+	}
+	if tenants.MaxTenants != 10000 {
+		t.Errorf("Expected MaxTenants default 10000 to be kept, got %d", tenants.MaxTenants)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.RateLimit.Tenants.Overrides["broken"] = TenantLimit{}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for override without a rate")
+	}
+}
+
+func TestLanesConfig(t *testing.T) {
+	os.Setenv("LANES_HIGH_WEIGHT", "16")
+	os.Setenv("LANES_LOW_WEIGHT", "2")
+	defer os.Unsetenv("LANES_HIGH_WEIGHT")
+	defer os.Unsetenv("LANES_LOW_WEIGHT")
+
+	cfg := DefaultConfig()
+	cfg.LoadFromEnv()
+
+	if cfg.Lanes.HighWeight != 16 {
+		t.Errorf("Expected HighWeight=16, got %d", cfg.Lanes.HighWeight)
+	}
+	if cfg.Lanes.NormalWeight != 4 {
+		t.Errorf("Expected NormalWeight=4, got %d", cfg.Lanes.NormalWeight)
+	}
+	if cfg.Lanes.LowWeight != 2 {
+		t.Errorf("Expected LowWeight=2, got %d", cfg.Lanes.LowWeight)
+	}
+
+	cfg.Lanes.LowWeight = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for zero lane weight")
+	}
+}
+
+func TestFairQueueConfig(t *testing.T) {
+	os.Setenv("FAIR_QUEUE_ENABLED", "true")
+	os.Setenv("FAIR_QUEUE_CAPACITY", "500")
+	defer os.Unsetenv("FAIR_QUEUE_ENABLED")
+	defer os.Unsetenv("FAIR_QUEUE_CAPACITY")
+
+	cfg := DefaultConfig()
+	cfg.LoadFromEnv()
+
+	if !cfg.FairQueue.Enabled {
+		t.Error("Expected FairQueue.Enabled=true")
+	}
+	if cfg.FairQueue.Capacity != 500 {
+		t.Errorf("Expected Capacity=500, got %d", cfg.FairQueue.Capacity)
+	}
+	if cfg.FairQueue.DefaultWeight != 1 {
+		t.Errorf("Expected DefaultWeight=1, got %d", cfg.FairQueue.DefaultWeight)
+	}
+
+	cfg.FairQueue.Weights = map[string]int{"bulk": 0}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for zero tenant weight")
+	}
+}
+
+func TestMetricsConfig(t *testing.T) {
+	os.Setenv("METRICS_ENABLED", "true")
+	os.Setenv("METRICS_ADDRESS", ":9100")
+	defer os.Unsetenv("METRICS_ENABLED")
+	defer os.Unsetenv("METRICS_ADDRESS")
+
+	cfg := DefaultConfig()
+	cfg.LoadFromEnv()
+
+	if !cfg.Metrics.Enabled {
+		t.Error("Expected Metrics.Enabled=true")
+	}
+	if cfg.Metrics.Address != ":9100" {
+		t.Errorf("Expected Address=:9100, got %s", cfg.Metrics.Address)
+	}
+	if cfg.Metrics.Path != "/metrics" {
+		t.Errorf("Expected Path=/metrics, got %s", cfg.Metrics.Path)
+	}
+
+	cfg.Metrics.Path = "metrics"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for metrics path without leading slash")
+	}
+}
+
+func TestTracingConfig(t *testing.T) {
+	os.Setenv("TRACING_ENABLED", "true")
+	os.Setenv("TRACING_OUTPUT", "/tmp/spans.jsonl")
+	defer os.Unsetenv("TRACING_ENABLED")
+	defer os.Unsetenv("TRACING_OUTPUT")
+
+	cfg := DefaultConfig()
+	cfg.LoadFromEnv()
+
+	if !cfg.Tracing.Enabled {
+		t.Error("Expected Tracing.Enabled=true")
+	}
+	if cfg.Tracing.Output != "/tmp/spans.jsonl" {
+		t.Errorf("Expected Output=/tmp/spans.jsonl, got %s", cfg.Tracing.Output)
+	}
+	if cfg.Tracing.ServiceName != "concurrent-pipeline-processor" {
+		t.Errorf("Expected default ServiceName, got %s", cfg.Tracing.ServiceName)
+	}
+
+	cfg.Tracing.Output = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for empty tracing output")
+	}
+}
+
+func TestAdminConfig(t *testing.T) {
+	os.Setenv("ADMIN_ENABLED", "true")
+	os.Setenv("ADMIN_ADDRESS", ":8181")
+	os.Setenv("ADMIN_DRAIN_TIMEOUT_MS", "5000")
+	defer os.Unsetenv("ADMIN_ENABLED")
+	defer os.Unsetenv("ADMIN_ADDRESS")
+	defer os.Unsetenv("ADMIN_DRAIN_TIMEOUT_MS")
+
+	cfg := DefaultConfig()
+	cfg.LoadFromEnv()
+
+	if !cfg.Admin.Enabled {
+		t.Error("Expected Admin.Enabled=true")
+	}
+	if cfg.Admin.Address != ":8181" {
+		t.Errorf("Expected Address=:8181, got %s", cfg.Admin.Address)
+	}
+	if cfg.Admin.DrainTimeoutMs != 5000 {
+		t.Errorf("Expected DrainTimeoutMs=5000, got %d", cfg.Admin.DrainTimeoutMs)
+	}
+
+	cfg.Admin.DrainTimeoutMs = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for zero drain timeout")
+	}
+}
+
+func TestIngestConfig(t *testing.T) {
+	os.Setenv("INGEST_ENABLED", "true")
+	os.Setenv("INGEST_MAX_BODY_BYTES", "4096")
+	os.Setenv("INGEST_STREAM_RESULTS", "true")
+	defer os.Unsetenv("INGEST_ENABLED")
+	defer os.Unsetenv("INGEST_MAX_BODY_BYTES")
+	defer os.Unsetenv("INGEST_STREAM_RESULTS")
+
+	cfg := DefaultConfig()
+	cfg.LoadFromEnv()
+
+	if !cfg.Ingest.Enabled {
+		t.Error("Expected Ingest.Enabled=true")
+	}
+	if cfg.Ingest.MaxBodyBytes != 4096 {
+		t.Errorf("Expected MaxBodyBytes=4096, got %d", cfg.Ingest.MaxBodyBytes)
+	}
+	if !cfg.Ingest.StreamResults {
+		t.Error("Expected Ingest.StreamResults=true")
+	}
+	if cfg.Ingest.MaxBatchSize != 1000 {
+		t.Errorf("Expected default MaxBatchSize=1000, got %d", cfg.Ingest.MaxBatchSize)
+	}
+
+	cfg.Ingest.MaxBatchSize = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for zero max batch size")
+	}
+}
+
+func TestValidationConfig(t *testing.T) {
+	os.Setenv("VALIDATION_MAX_OPERATIONS", "5")
+	os.Setenv("VALIDATION_MIN_VALUE", "-10")
+	os.Setenv("VALIDATION_ALLOWED_OPERATORS", "plus, multiply")
+	os.Setenv("VALIDATION_REJECT_POSSIBLE_OVERFLOW", "true")
+	defer os.Unsetenv("VALIDATION_MAX_OPERATIONS")
+	defer os.Unsetenv("VALIDATION_MIN_VALUE")
+	defer os.Unsetenv("VALIDATION_ALLOWED_OPERATORS")
+	defer os.Unsetenv("VALIDATION_REJECT_POSSIBLE_OVERFLOW")
+
+	cfg := DefaultConfig()
+	cfg.LoadFromEnv()
+
+	rules, err := cfg.ValidationRules()
+	if err != nil {
+		t.Fatalf("ValidationRules() error = %v", err)
+	}
+	if rules.MaxOperations != 5 {
+		t.Errorf("Expected MaxOperations=5, got %d", rules.MaxOperations)
+	}
+	if rules.MinValue == nil || *rules.MinValue != -10 {
+		t.Errorf("Expected MinValue=-10, got %v", rules.MinValue)
+	}
+	if rules.MaxValue != nil {
+		t.Errorf("Expected MaxValue unset, got %d", *rules.MaxValue)
+	}
+	if !rules.RejectPossibleOverflow {
+		t.Error("Expected RejectPossibleOverflow=true")
+	}
+	want := []models.Operator{models.OperatorPlus, models.OperatorMultiply}
+	if len(rules.AllowedOperators) != len(want) || rules.AllowedOperators[0] != want[0] || rules.AllowedOperators[1] != want[1] {
+		t.Errorf("Expected AllowedOperators=%v, got %v", want, rules.AllowedOperators)
+	}
+
+	cfg.Validation.AllowedOperators = []string{"modulo"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown operator")
+	}
+
+	cfg.Validation.AllowedOperators = nil
+	maxValue := -20
+	cfg.Validation.MaxValue = &maxValue
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for min value above max value")
+	}
+}
+
+func TestArithmeticConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	if mode, err := cfg.ArithmeticMode(); err != nil || mode != processor.ModeInt {
+		t.Errorf("Expected default mode int, got %v (%v)", mode, err)
+	}
+
+	os.Setenv("ARITHMETIC_MODE", "big")
+	defer os.Unsetenv("ARITHMETIC_MODE")
+	cfg.LoadFromEnv()
+
+	rules, err := cfg.ValidationRules()
+	if err != nil {
+		t.Fatalf("ValidationRules() error = %v", err)
+	}
+	if rules.Mode != processor.ModeBig {
+		t.Errorf("Expected rules mode big, got %v", rules.Mode)
+	}
+
+	if cfg.Arithmetic.ExplainErrors {
+		t.Error("Expected explaining errors to be disabled by default")
+	}
+	os.Setenv("ARITHMETIC_EXPLAIN_ERRORS", "true")
+	defer os.Unsetenv("ARITHMETIC_EXPLAIN_ERRORS")
+	cfg.LoadFromEnv()
+	if !cfg.Arithmetic.ExplainErrors {
+		t.Error("Expected ARITHMETIC_EXPLAIN_ERRORS to enable explaining errors")
+	}
+
+	os.Setenv("ARITHMETIC_PROGRAM_CACHE_SIZE", "256")
+	defer os.Unsetenv("ARITHMETIC_PROGRAM_CACHE_SIZE")
+	cfg.LoadFromEnv()
+	if cfg.Arithmetic.ProgramCacheSize != 256 {
+		t.Errorf("Expected program cache size 256, got %d", cfg.Arithmetic.ProgramCacheSize)
+	}
+	cfg.Arithmetic.ProgramCacheSize = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative program cache size")
+	}
+	cfg.Arithmetic.ProgramCacheSize = 0
+
+	cfg.Arithmetic.Mode = "float128"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown arithmetic mode")
+	}
+}
+
+func TestDecimalConfig(t *testing.T) {
+	os.Setenv("ARITHMETIC_SCALE", "4")
+	os.Setenv("ARITHMETIC_ROUNDING", "truncate")
+	defer os.Unsetenv("ARITHMETIC_SCALE")
+	defer os.Unsetenv("ARITHMETIC_ROUNDING")
+
+	cfg := DefaultConfig()
+	cfg.LoadFromEnv()
+
+	opts, err := cfg.DecimalOptions()
+	if err != nil {
+		t.Fatalf("DecimalOptions() error = %v", err)
+	}
+	if opts.Scale != 4 || opts.Rounding != processor.RoundTruncate {
+		t.Errorf("Expected scale 4 and truncate, got %d and %s", opts.Scale, opts.Rounding)
+	}
+
+	cfg.Arithmetic.Rounding = "ceiling"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unknown rounding")
+	}
+	cfg.Arithmetic.Rounding = "half_up"
+	cfg.Arithmetic.Scale = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative scale")
+	}
+}
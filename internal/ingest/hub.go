@@ -0,0 +1,138 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// defaultSubscriberBuffer is the number of results buffered per stream
+const defaultSubscriberBuffer = 256
+
+// Hub fans results out to the connected result streams. A stream that falls
+// behind loses results rather than slowing down the pipeline.
+type Hub struct {
+	mu      sync.Mutex
+	subs    map[chan models.Result]struct{}
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// NewHub creates a hub without subscribers
+func NewHub() *Hub {
+	return &Hub{subs: make(map[chan models.Result]struct{})}
+}
+
+// Publish sends result to every subscriber that has room for it
+func (h *Hub) Publish(result models.Result) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs {
+		select {
+		case ch <- result:
+		default:
+			h.dropped.Add(1)
+		}
+	}
+}
+
+// Close ends every stream; later subscribers receive a closed channel
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for ch := range h.subs {
+		close(ch)
+		delete(h.subs, ch)
+	}
+}
+
+// Dropped returns the number of results discarded for slow subscribers
+func (h *Hub) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// subscribe registers a stream and returns its channel and a function that
+// unregisters it
+func (h *Hub) subscribe() (<-chan models.Result, func()) {
+	ch := make(chan models.Result, defaultSubscriberBuffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subs[ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// resultEvent is the JSON payload of a result event
+type resultEvent struct {
+	Result      json.Number         `json:"result"`
+	Error       string              `json:"error,omitempty"`
+	Code        models.ErrorCode    `json:"code,omitempty"`
+	Explanation *models.Explanation `json:"explanation,omitempty"`
+}
+
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming unsupported"))
+		return
+	}
+
+	results, unsubscribe := s.opts.Hub.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				fmt.Fprint(w, "event: end\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			event := resultEvent{Result: json.Number(result.Exact())}
+			if result.Error != nil {
+				event.Error = result.Error.Error()
+				event.Code = models.CodeOf(result.Error)
+				event.Explanation = result.Explanation
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: result\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package ingest
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+
+	results, unsubscribe := hub.subscribe()
+	hub.Publish(models.Result{Result: 1})
+	if got := <-results; got.Result != 1 {
+		t.Errorf("Expected result 1, got %d", got.Result)
+	}
+
+	// A full subscriber loses results instead of blocking Publish
+	for i := 0; i < defaultSubscriberBuffer+3; i++ {
+		hub.Publish(models.Result{Result: i})
+	}
+	if got := hub.Dropped(); got != 3 {
+		t.Errorf("Expected 3 dropped results, got %d", got)
+	}
+
+	unsubscribe()
+	unsubscribe()
+	hub.Publish(models.Result{Result: 2})
+
+	hub.Close()
+	late, _ := hub.subscribe()
+	if _, ok := <-late; ok {
+		t.Error("Expected subscription after Close to be closed")
+	}
+}
+
+func TestResultStream(t *testing.T) {
+	hub := NewHub()
+	srv := httptest.NewServer(New(Options{Hub: hub}).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/results/stream")
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, got %s", ct)
+	}
+
+	// Wait for the handler to subscribe before publishing
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		hub.mu.Lock()
+		n := len(hub.subs)
+		hub.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout waiting for stream subscription")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	hub.Publish(models.Result{Result: 42})
+	hub.Publish(models.Result{Error: models.NewError(models.CodeDivisionByZero, "division by zero")})
+	hub.Close()
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			events = append(events, line)
+		}
+	}
+
+	want := []string{
+		"event: result",
+		`data: {"result":42}`,
+		"event: result",
+		`data: {"result":0,"error":"division by zero","code":"division_by_zero"}`,
+		"event: end",
+		"data: {}",
+	}
+	if strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected stream\ngot:\n%s\nwant:\n%s", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestStreamDisabledWithoutHub(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(Options{}).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/results/stream", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
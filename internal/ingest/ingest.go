@@ -0,0 +1,263 @@
+// Package ingest accepts tasks over HTTP and streams pipeline results back to
+// clients as server-sent events.
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"concurrent-pipeline-processor/internal/pipeline"
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+var (
+	// ErrInvalidRequest is wrapped by the errors of malformed requests
+	ErrInvalidRequest = models.NewError(models.CodeInvalidRequest, "invalid request body")
+	// ErrRequestTooLarge is wrapped by the errors of requests over the size limits
+	ErrRequestTooLarge = models.NewError(models.CodeRequestTooLarge, "request too large")
+)
+
+const (
+	defaultMaxBatchSize = 1000
+	defaultMaxBodyBytes = 1 << 20
+	defaultRetryAfter   = time.Second
+)
+
+// Options configures a Server
+type Options struct {
+	// Pipeline receives the submitted tasks
+	Pipeline pipeline.Pipeline
+	// Hub streams results on GET /results/stream when set
+	Hub *Hub
+	// MaxBatchSize limits the number of tasks in one batch; zero selects 1000
+	MaxBatchSize int
+	// MaxBodyBytes limits the size of a request body; zero selects 1 MiB
+	MaxBodyBytes int64
+	// Rules are checked before a task is submitted, in addition to the
+	// built-in checks; they should match the pipeline's validation rules
+	Rules processor.Rules
+	// RetryAfter is advertised to clients rejected by a retryable error;
+	// zero selects one second
+	RetryAfter time.Duration
+}
+
+// Server implements the ingestion endpoints
+type Server struct {
+	opts Options
+}
+
+// New creates an ingestion server for the given options
+func New(opts Options) *Server {
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = defaultMaxBatchSize
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = defaultRetryAfter
+	}
+	return &Server{opts: opts}
+}
+
+// Handler returns the HTTP handler serving the ingestion endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /tasks", s.handleTask)
+	mux.HandleFunc("POST /tasks/batch", s.handleBatch)
+	if s.opts.Hub != nil {
+		mux.HandleFunc("GET /results/stream", s.handleStream)
+	}
+	return mux
+}
+
+func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) {
+	var task models.Task
+	if status, err := s.decode(w, r, &task); err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	status, err := s.submit(r, task)
+	if err != nil {
+		if retryable(err) {
+			s.setRetryAfter(w)
+		}
+		writeError(w, status, err)
+		return
+	}
+	writeJSON(w, status, taskResponse{Status: "accepted"})
+}
+
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var tasks []models.Task
+	if status, err := s.decode(w, r, &tasks); err != nil {
+		writeError(w, status, err)
+		return
+	}
+	if len(tasks) == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: batch is empty", ErrInvalidRequest))
+		return
+	}
+	if len(tasks) > s.opts.MaxBatchSize {
+		writeError(w, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("%w: batch of %d tasks exceeds the limit of %d", ErrRequestTooLarge, len(tasks), s.opts.MaxBatchSize))
+		return
+	}
+
+	resp := batchResponse{Items: make([]itemResponse, len(tasks))}
+	retry := false
+	for i, task := range tasks {
+		status, err := s.submit(r, task)
+		resp.Items[i] = itemResponse{Index: i, Status: status}
+		if err != nil {
+			resp.Items[i].Error = err.Error()
+			resp.Items[i].Code = models.CodeOf(err)
+			resp.Items[i].Violations = violations(err)
+			retry = retry || retryable(err)
+			continue
+		}
+		resp.Accepted++
+	}
+
+	status := http.StatusAccepted
+	if resp.Accepted < len(tasks) {
+		status = http.StatusMultiStatus
+	}
+	if retry {
+		s.setRetryAfter(w)
+	}
+	writeJSON(w, status, resp)
+}
+
+// decode reads a JSON request body into v, returning the status to reject
+// the request with on failure
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v interface{}) (int, error) {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.opts.MaxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return http.StatusRequestEntityTooLarge, fmt.Errorf("%w: %w", ErrRequestTooLarge, err)
+		}
+		return http.StatusBadRequest, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return http.StatusBadRequest, fmt.Errorf("%w: trailing data", ErrInvalidRequest)
+	}
+	return 0, nil
+}
+
+// submit validates a task and adds it to the pipeline, returning the status
+// describing the outcome
+func (s *Server) submit(r *http.Request, task models.Task) (int, error) {
+	if err := s.opts.Rules.ValidateTask(task); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	if err := s.opts.Pipeline.AddTaskContext(r.Context(), task); err != nil {
+		return statusFor(err), err
+	}
+	return http.StatusAccepted, nil
+}
+
+// statusFor maps an AddTask error to an HTTP status
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, pipeline.ErrRateLimitExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, pipeline.ErrBufferFull),
+		errors.Is(err, pipeline.ErrIntakePaused),
+		errors.Is(err, pipeline.ErrPipelinePaused),
+		errors.Is(err, pipeline.ErrPipelineNotStarted),
+		errors.Is(err, pipeline.ErrPipelineStopped):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, pipeline.ErrInvalidPriority):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// retryable reports whether a task rejected with err may succeed if retried
+// later without changes
+func retryable(err error) bool {
+	return errors.Is(err, pipeline.ErrRateLimitExceeded) ||
+		errors.Is(err, pipeline.ErrBufferFull) ||
+		errors.Is(err, pipeline.ErrIntakePaused) ||
+		errors.Is(err, pipeline.ErrPipelinePaused)
+}
+
+func (s *Server) setRetryAfter(w http.ResponseWriter) {
+	seconds := int(math.Ceil(s.opts.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
+type taskResponse struct {
+	Status string `json:"status"`
+}
+
+type itemResponse struct {
+	Index      int                 `json:"index"`
+	Status     int                 `json:"status"`
+	Error      string              `json:"error,omitempty"`
+	Code       models.ErrorCode    `json:"code,omitempty"`
+	Violations []violationResponse `json:"violations,omitempty"`
+}
+
+type batchResponse struct {
+	Accepted int            `json:"accepted"`
+	Items    []itemResponse `json:"items"`
+}
+
+type errorResponse struct {
+	Error      string              `json:"error"`
+	Code       models.ErrorCode    `json:"code"`
+	Violations []violationResponse `json:"violations,omitempty"`
+}
+
+// violationResponse is the JSON form of a processor.Violation; Index is -1
+// for violations of the task as a whole
+type violationResponse struct {
+	Index    int              `json:"index"`
+	Operator int              `json:"operator"`
+	Value    int              `json:"value"`
+	Code     models.ErrorCode `json:"code"`
+	Message  string           `json:"message"`
+}
+
+// violations lists the violations of a validation error, if err is one
+func violations(err error) []violationResponse {
+	var verr *processor.ValidationError
+	if !errors.As(err, &verr) {
+		return nil
+	}
+
+	resp := make([]violationResponse, len(verr.Violations))
+	for i, v := range verr.Violations {
+		resp[i] = violationResponse{
+			Index:    v.Index,
+			Operator: int(v.Operator),
+			Value:    v.Value,
+			Code:     v.Code,
+			Message:  v.Err.Error(),
+		}
+	}
+	return resp
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error(), Code: models.CodeOf(err), Violations: violations(err)})
+}
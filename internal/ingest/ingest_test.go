@@ -0,0 +1,232 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/pipeline"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func newTestPipeline(t *testing.T, opts pipeline.Options) pipeline.Pipeline {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	opts.InputBufferSize = 100
+	opts.ResultBufferSize = 100
+	p, err := pipeline.NewPipeline(opts)
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	return p
+}
+
+func post(h http.Handler, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestSubmitTask(t *testing.T) {
+	const valid = `{"value": 2, "operations": [{"operator": 0, "value": 3}], "tenant": "alpha"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		setup          func(p pipeline.Pipeline)
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{name: "accepted", body: valid, wantStatus: http.StatusAccepted},
+		{name: "malformed", body: `{"value":`, wantStatus: http.StatusBadRequest},
+		{name: "unknown field", body: `{"value": 1, "operations": [], "colour": "red"}`, wantStatus: http.StatusBadRequest},
+		{name: "trailing data", body: valid + `{}`, wantStatus: http.StatusBadRequest},
+		{name: "validation failure", body: `{"value": 1}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "invalid operator", body: `{"value": 1, "operations": [{"operator": 9}]}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "invalid priority", body: `{"value": 1, "operations": [], "priority": 7}`, wantStatus: http.StatusUnprocessableEntity},
+		{
+			name:           "intake paused",
+			body:           valid,
+			setup:          func(p pipeline.Pipeline) { p.PauseIntake() },
+			wantStatus:     http.StatusServiceUnavailable,
+			wantRetryAfter: "2",
+		},
+		{
+			name: "stopped",
+			body: valid,
+			setup: func(p pipeline.Pipeline) {
+				_ = p.Drain(context.Background())
+			},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPipeline(t, pipeline.Options{
+				NumWorkers:        1,
+				AggregationWindow: 1,
+				TasksPerSecond:    100,
+				BurstSize:         100,
+			})
+			if tt.setup != nil {
+				tt.setup(p)
+			}
+			h := New(Options{Pipeline: p, RetryAfter: 1500 * time.Millisecond}).Handler()
+
+			rec := post(h, "/tasks", tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.wantRetryAfter, got)
+			}
+		})
+	}
+}
+
+func TestSubmitTaskRateLimited(t *testing.T) {
+	p := newTestPipeline(t, pipeline.Options{
+		NumWorkers:        1,
+		AggregationWindow: 1,
+		TasksPerSecond:    1,
+		BurstSize:         1,
+	})
+	h := New(Options{Pipeline: p}).Handler()
+
+	body := `{"value": 1, "operations": []}`
+	if rec := post(h, "/tasks", body); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", rec.Code)
+	}
+
+	rec := post(h, "/tasks", body)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", got)
+	}
+}
+
+func TestSubmitBatch(t *testing.T) {
+	p := newTestPipeline(t, pipeline.Options{
+		NumWorkers:        1,
+		AggregationWindow: 1,
+		TasksPerSecond:    2,
+		BurstSize:         2,
+	})
+	h := New(Options{Pipeline: p, MaxBatchSize: 4}).Handler()
+
+	body := `[
+		{"value": 1, "operations": []},
+		{"value": 1},
+		{"value": 2, "operations": []},
+		{"value": 3, "operations": []}
+	]`
+	rec := post(h, "/tasks/batch", body)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status 207, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After for a rate limited item")
+	}
+
+	var resp batchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Accepted != 2 {
+		t.Errorf("Expected 2 accepted, got %d", resp.Accepted)
+	}
+	want := []int{http.StatusAccepted, http.StatusUnprocessableEntity, http.StatusAccepted, http.StatusTooManyRequests}
+	wantCodes := []models.ErrorCode{"", models.CodeNilOperations, "", models.CodeRateLimited}
+	for i, item := range resp.Items {
+		if item.Index != i || item.Status != want[i] {
+			t.Errorf("Expected item %d status %d, got index %d status %d", i, want[i], item.Index, item.Status)
+		}
+		if item.Code != wantCodes[i] {
+			t.Errorf("Expected item %d code %q, got %q", i, wantCodes[i], item.Code)
+		}
+	}
+
+	if rec := post(h, "/tasks/batch", `[]`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for empty batch, got %d", rec.Code)
+	}
+	large := "[" + strings.Repeat(`{"value": 1, "operations": []},`, 4) + `{"value": 1, "operations": []}]`
+	if rec := post(h, "/tasks/batch", large); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for oversized batch, got %d", rec.Code)
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	p := newTestPipeline(t, pipeline.Options{NumWorkers: 1, AggregationWindow: 1, TasksPerSecond: 1})
+	h := New(Options{Pipeline: p, MaxBodyBytes: 16}).Handler()
+
+	rec := post(h, "/tasks", `{"value": 1, "operations": [], "tenant": "a-very-long-tenant-name"}`)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", rec.Code)
+	}
+}
+
+func TestStatusFor(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{pipeline.ErrRateLimitExceeded, http.StatusTooManyRequests},
+		{pipeline.ErrTenantRateLimitExceeded, http.StatusTooManyRequests},
+		{pipeline.ErrBufferFull, http.StatusServiceUnavailable},
+		{pipeline.ErrIntakePaused, http.StatusServiceUnavailable},
+		{pipeline.ErrPipelinePaused, http.StatusServiceUnavailable},
+		{pipeline.ErrPipelineStopped, http.StatusServiceUnavailable},
+		{pipeline.ErrInvalidPriority, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := statusFor(tt.err); got != tt.want {
+				t.Errorf("statusFor(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitTaskViolations(t *testing.T) {
+	p := newTestPipeline(t, pipeline.Options{NumWorkers: 1, AggregationWindow: 1, TasksPerSecond: 1})
+	h := New(Options{Pipeline: p}).Handler()
+
+	rec := post(h, "/tasks", `{"value": 1, "operations": [{"operator": 9, "value": 1}, {"operator": 0}, {"operator": 2, "value": 0}]}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", rec.Code)
+	}
+
+	var resp errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Code != models.CodeInvalidOperator {
+		t.Errorf("Expected code %s, got %s", models.CodeInvalidOperator, resp.Code)
+	}
+	want := []violationResponse{
+		{Index: 0, Operator: 9, Value: 1, Code: "invalid_operator", Message: "invalid operator"},
+		{Index: 2, Operator: 2, Value: 0, Code: "division_by_zero", Message: "division by zero"},
+	}
+	if len(resp.Violations) != len(want) {
+		t.Fatalf("Expected %d violations, got %+v", len(want), resp.Violations)
+	}
+	for i, v := range resp.Violations {
+		if v != want[i] {
+			t.Errorf("Expected violation %+v, got %+v", want[i], v)
+		}
+	}
+}
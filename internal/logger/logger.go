@@ -1,14 +1,22 @@
 package logger
 
 import (
+	"errors"
 	"io"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
 )
 
-var log zerolog.Logger
+// ErrInvalidLevel is returned by SetLevel for an unknown level name
+var ErrInvalidLevel = errors.New("invalid log level")
+
+var (
+	log zerolog.Logger
+	mu  sync.RWMutex
+)
 
 // Config holds logger configuration
 type Config struct {
@@ -41,6 +49,9 @@ func Initialize(cfg Config) {
 		level = zerolog.DebugLevel
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
+
 	log = zerolog.New(output).
 		Level(level).
 		With().
@@ -49,44 +60,83 @@ func Initialize(cfg Config) {
 		Logger()
 }
 
-// GetLogger returns the configured logger instance
+// GetLogger returns a copy of the configured logger instance. The copy does
+// not follow later SetLevel calls; use the package functions for that.
 func GetLogger() zerolog.Logger {
-	return log
+	return *current()
+}
+
+// SetLevel changes the level of the package logger at runtime
+func SetLevel(level string) error {
+	if !validLevel(level) {
+		return ErrInvalidLevel
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	log = log.Level(getLogLevel(level))
+	return nil
+}
+
+// Level returns the current level of the package logger
+func Level() string {
+	return current().GetLevel().String()
+}
+
+// current returns a copy of the package logger, safe to use while SetLevel
+// replaces it
+func current() *zerolog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	l := log
+	return &l
 }
 
 // Debug logs a debug message
 func Debug() *zerolog.Event {
-	return log.Debug()
+	return current().Debug()
 }
 
 // Info logs an info message
 func Info() *zerolog.Event {
-	return log.Info()
+	return current().Info()
 }
 
 // Warn logs a warning message
 func Warn() *zerolog.Event {
-	return log.Warn()
+	return current().Warn()
 }
 
 // Error logs an error message
 func Error() *zerolog.Event {
-	return log.Error()
+	return current().Error()
 }
 
 // Fatal logs a fatal message and exits
 func Fatal() *zerolog.Event {
-	return log.Fatal()
+	return current().Fatal()
 }
 
 // WithError adds an error to the log event
 func WithError(err error) *zerolog.Event {
-	return log.Error().Err(err)
+	return current().Error().Err(err)
 }
 
 // WithField adds a field to the log event
 func WithField(key string, value interface{}) zerolog.Logger {
-	return log.With().Interface(key, value).Logger()
+	return current().With().Interface(key, value).Logger()
+}
+
+// validLevel reports whether level is a name getLogLevel understands
+func validLevel(level string) bool {
+	switch level {
+	case "debug", "info", "warn", "error", "fatal":
+		return true
+	default:
+		return false
+	}
 }
 
 // getLogLevel converts a string level to zerolog.Level
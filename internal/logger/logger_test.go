@@ -179,6 +179,35 @@ func TestGetLogLevel(t *testing.T) {
 	}
 }
 
+func TestSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log = zerolog.New(&buf).Level(zerolog.InfoLevel)
+
+	Debug().Msg("dropped")
+	if buf.Len() != 0 {
+		t.Fatalf("Expected debug message to be dropped at info level, got %s", buf.String())
+	}
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	if got := Level(); got != "debug" {
+		t.Errorf("Expected level debug, got %s", got)
+	}
+
+	Debug().Msg("kept")
+	if !strings.Contains(buf.String(), "kept") {
+		t.Error("Expected debug message to be logged after SetLevel")
+	}
+
+	if err := SetLevel("verbose"); err != ErrInvalidLevel {
+		t.Errorf("Expected ErrInvalidLevel, got %v", err)
+	}
+	if got := Level(); got != "debug" {
+		t.Errorf("Expected level to stay debug after invalid SetLevel, got %s", got)
+	}
+}
+
 // testError is a simple error implementation for testing
 type testError struct {
 	msg string
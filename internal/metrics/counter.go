@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	value atomic.Uint64
+}
+
+// Inc increments the counter by one
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add increments the counter by n
+func (c *Counter) Add(n uint64) {
+	c.value.Add(n)
+}
+
+// Value returns the current count
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+// CounterVec is a family of counters partitioned by label values
+type CounterVec struct {
+	desc
+	children series[*Counter]
+}
+
+// NewCounter registers and returns an unlabelled counter
+func (r *Registry) NewCounter(name, help string) *Counter {
+	return r.NewCounterVec(name, help).With()
+}
+
+// NewCounterVec registers and returns a counter family with the given label names
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	v := &CounterVec{desc: desc{metricName: name, help: help, kind: "counter", labelNames: labelNames}}
+	r.register(v)
+	return v
+}
+
+// With returns the counter for the label values, creating it on first use
+func (v *CounterVec) With(labelValues ...string) *Counter {
+	checkLabels(v.desc, labelValues)
+	return v.children.get(labelValues, func() *Counter { return &Counter{} })
+}
+
+// Lookup returns the counter for the label values if it has been created,
+// without creating it
+func (v *CounterVec) Lookup(labelValues ...string) (*Counter, bool) {
+	checkLabels(v.desc, labelValues)
+	return v.children.lookup(labelValues)
+}
+
+// Total returns the sum of every counter in the family
+func (v *CounterVec) Total() uint64 {
+	var total uint64
+	_ = v.children.each(func(_ []string, c *Counter) error {
+		total += c.Value()
+		return nil
+	})
+	return total
+}
+
+func (v *CounterVec) write(w io.Writer) error {
+	if err := v.writeHeader(w); err != nil {
+		return err
+	}
+	return v.children.each(func(values []string, c *Counter) error {
+		_, err := fmt.Fprintf(w, "%s%s %d\n", v.metricName, formatLabels(v.labelNames, values, "", ""), c.Value())
+		return err
+	})
+}
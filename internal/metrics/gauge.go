@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+)
+
+// GaugeFuncVec is a family of gauges whose values are read from callbacks
+// when the registry is rendered
+type GaugeFuncVec struct {
+	desc
+	children series[func() float64]
+}
+
+// NewGaugeFunc registers an unlabelled gauge that reports the value of fn
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.NewGaugeFuncVec(name, help).Set(fn)
+}
+
+// NewGaugeFuncVec registers and returns a callback gauge family with the given label names
+func (r *Registry) NewGaugeFuncVec(name, help string, labelNames ...string) *GaugeFuncVec {
+	v := &GaugeFuncVec{desc: desc{metricName: name, help: help, kind: "gauge", labelNames: labelNames}}
+	r.register(v)
+	return v
+}
+
+// Set registers fn as the source of the gauge with the given label values.
+// Only the first callback registered for a set of label values is kept.
+func (v *GaugeFuncVec) Set(fn func() float64, labelValues ...string) {
+	checkLabels(v.desc, labelValues)
+	v.children.get(labelValues, func() func() float64 { return fn })
+}
+
+func (v *GaugeFuncVec) write(w io.Writer) error {
+	if err := v.writeHeader(w); err != nil {
+		return err
+	}
+	return v.children.each(func(values []string, fn func() float64) error {
+		_, err := fmt.Fprintf(w, "%s%s %s\n", v.metricName, formatLabels(v.labelNames, values, "", ""), formatFloat(fn()))
+		return err
+	})
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// ExponentialBuckets returns count bucket upper bounds starting at start and
+// multiplied by factor each step
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// Histogram counts observations into cumulative buckets
+type Histogram struct {
+	upperBounds []float64
+	counts      []atomic.Uint64
+	count       atomic.Uint64
+	sum         atomicFloat
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		upperBounds: buckets,
+		counts:      make([]atomic.Uint64, len(buckets)),
+	}
+}
+
+// Observe adds a single observation
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upperBounds, v)
+	if i < len(h.counts) {
+		h.counts[i].Add(1)
+	}
+	h.count.Add(1)
+	h.sum.add(v)
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	return h.count.Load()
+}
+
+// Sum returns the sum of all observations
+func (h *Histogram) Sum() float64 {
+	return h.sum.load()
+}
+
+// HistogramVec is a family of histograms partitioned by label values
+type HistogramVec struct {
+	desc
+	buckets  []float64
+	children series[*Histogram]
+}
+
+// NewHistogram registers and returns an unlabelled histogram
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	return r.NewHistogramVec(name, help, buckets).With()
+}
+
+// NewHistogramVec registers and returns a histogram family with the given
+// bucket upper bounds and label names
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	v := &HistogramVec{
+		desc:    desc{metricName: name, help: help, kind: "histogram", labelNames: labelNames},
+		buckets: sorted,
+	}
+	r.register(v)
+	return v
+}
+
+// With returns the histogram for the label values, creating it on first use
+func (v *HistogramVec) With(labelValues ...string) *Histogram {
+	checkLabels(v.desc, labelValues)
+	return v.children.get(labelValues, func() *Histogram { return newHistogram(v.buckets) })
+}
+
+func (v *HistogramVec) write(w io.Writer) error {
+	if err := v.writeHeader(w); err != nil {
+		return err
+	}
+	return v.children.each(func(values []string, h *Histogram) error {
+		var cumulative uint64
+		for i, bound := range h.upperBounds {
+			cumulative += h.counts[i].Load()
+			labels := formatLabels(v.labelNames, values, "le", formatFloat(bound))
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", v.metricName, labels, cumulative); err != nil {
+				return err
+			}
+		}
+
+		count := h.Count()
+		labels := formatLabels(v.labelNames, values, "le", formatFloat(math.Inf(1)))
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", v.metricName, labels, count); err != nil {
+			return err
+		}
+
+		plain := formatLabels(v.labelNames, values, "", "")
+		_, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", v.metricName, plain, formatFloat(h.Sum()), v.metricName, plain, count)
+		return err
+	})
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExponentialBuckets(t *testing.T) {
+	got := ExponentialBuckets(1, 2, 4)
+	want := []float64{1, 2, 4, 8}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ExponentialBuckets() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	reg := NewRegistry()
+	vec := reg.NewHistogramVec("stage_seconds", "Stage latency.", []float64{0.1, 1}, "stage")
+
+	h := vec.With("validator")
+	for _, v := range []float64{0.05, 0.1, 0.5, 2} {
+		h.Observe(v)
+	}
+
+	if h.Count() != 4 {
+		t.Errorf("Expected count 4, got %d", h.Count())
+	}
+	if h.Sum() != 2.65 {
+		t.Errorf("Expected sum 2.65, got %v", h.Sum())
+	}
+
+	var buf bytes.Buffer
+	if err := reg.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `# HELP stage_seconds Stage latency.
+# TYPE stage_seconds histogram
+stage_seconds_bucket{stage="validator",le="0.1"} 2
+stage_seconds_bucket{stage="validator",le="1"} 3
+stage_seconds_bucket{stage="validator",le="+Inf"} 4
+stage_seconds_sum{stage="validator"} 2.65
+stage_seconds_count{stage="validator"} 4
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteText() output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
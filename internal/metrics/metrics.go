@@ -0,0 +1,215 @@
+// Package metrics implements counters, gauges and histograms that can be
+// exposed in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// collector is implemented by every metric family held by a Registry
+type collector interface {
+	name() string
+	write(w io.Writer) error
+}
+
+// Registry holds metric families and renders them in the text format
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.collectors[c.name()]; exists {
+		panic(fmt.Sprintf("metrics: duplicate registration of %q", c.name()))
+	}
+	r.collectors[c.name()] = c
+}
+
+// WriteText writes every registered metric in the Prometheus text format,
+// ordered by metric name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].name() < collectors[j].name()
+	})
+
+	for _, c := range collectors {
+		if err := c.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler returns an HTTP handler serving the registry in the text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+// desc describes a metric family
+type desc struct {
+	metricName string
+	help       string
+	kind       string
+	labelNames []string
+}
+
+func (d desc) name() string {
+	return d.metricName
+}
+
+func (d desc) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, escapeHelp(d.help), d.metricName, d.kind)
+	return err
+}
+
+// labelKey joins label values into a map key
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// formatLabels renders label pairs, with optional extra pair, as {a="b",...}
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, n, escapeLabel(values[i]))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extraName, escapeLabel(extraValue))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer("\\", `\\`, "\n", `\n`, `"`, `\"`).Replace(v)
+}
+
+func escapeHelp(v string) string {
+	return strings.NewReplacer("\\", `\\`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// atomicFloat is a float64 updated with compare-and-swap
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) add(v float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+// series keeps the children of a labelled family in creation order
+type series[T any] struct {
+	mu     sync.Mutex
+	keys   []string
+	values map[string][]string
+	items  map[string]T
+}
+
+func (s *series[T]) get(labelValues []string, create func() T) T {
+	key := labelKey(labelValues)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if item, ok := s.items[key]; ok {
+		return item
+	}
+	if s.items == nil {
+		s.items = make(map[string]T)
+		s.values = make(map[string][]string)
+	}
+	item := create()
+	s.items[key] = item
+	s.values[key] = append([]string(nil), labelValues...)
+	s.keys = append(s.keys, key)
+	return item
+}
+
+func (s *series[T]) lookup(labelValues []string) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[labelKey(labelValues)]
+	return item, ok
+}
+
+func (s *series[T]) each(fn func(labelValues []string, item T) error) error {
+	s.mu.Lock()
+	keys := append([]string(nil), s.keys...)
+	s.mu.Unlock()
+
+	for _, key := range keys {
+		s.mu.Lock()
+		values, item := s.values[key], s.items[key]
+		s.mu.Unlock()
+		if err := fn(values, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkLabels(d desc, values []string) {
+	if len(values) != len(d.labelNames) {
+		panic(fmt.Sprintf("metrics: %q expects %d label values, got %d", d.metricName, len(d.labelNames), len(values)))
+	}
+}
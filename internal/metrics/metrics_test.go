@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	reg := NewRegistry()
+
+	accepted := reg.NewCounter("tasks_accepted_total", "Tasks accepted.")
+	rejected := reg.NewCounterVec("tasks_rejected_total", "Tasks rejected.", "reason")
+	depth := 3.0
+	reg.NewGaugeFunc("queue_depth", "Queued tasks.", func() float64 { return depth })
+
+	accepted.Add(2)
+	accepted.Inc()
+	rejected.With("rate_limit").Inc()
+	rejected.With(`quote"back\slash`).Add(4)
+
+	var buf bytes.Buffer
+	if err := reg.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `# HELP queue_depth Queued tasks.
+# TYPE queue_depth gauge
+queue_depth 3
+# HELP tasks_accepted_total Tasks accepted.
+# TYPE tasks_accepted_total counter
+tasks_accepted_total 3
+# HELP tasks_rejected_total Tasks rejected.
+# TYPE tasks_rejected_total counter
+tasks_rejected_total{reason="rate_limit"} 1
+tasks_rejected_total{reason="quote\"back\\slash"} 4
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteText() output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if total := rejected.Total(); total != 5 {
+		t.Errorf("Expected rejected total 5, got %d", total)
+	}
+}
+
+func TestCounterVecLookup(t *testing.T) {
+	reg := NewRegistry()
+	vec := reg.NewCounterVec("looked_up_total", "help", "reason")
+	vec.With("seen").Inc()
+
+	if c, ok := vec.Lookup("seen"); !ok || c.Value() != 1 {
+		t.Errorf("Expected the existing counter with value 1, got %v, %v", c, ok)
+	}
+	if _, ok := vec.Lookup("unseen"); ok {
+		t.Error("Expected no counter for unseen label values")
+	}
+
+	var buf bytes.Buffer
+	if err := reg.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "unseen") {
+		t.Errorf("Expected Lookup not to create a series, got:\n%s", buf.String())
+	}
+}
+
+func TestRegistryDuplicateRegistration(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("dup_total", "first")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on duplicate registration")
+		}
+	}()
+	reg.NewCounter("dup_total", "second")
+}
+
+func TestCounterVecLabelCount(t *testing.T) {
+	reg := NewRegistry()
+	vec := reg.NewCounterVec("labelled_total", "help", "a", "b")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on wrong number of label values")
+		}
+	}()
+	vec.With("only-one")
+}
+
+func TestRegistryHandler(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("requests_total", "Requests.").Inc()
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Expected Prometheus text content type, got %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "requests_total 1\n") {
+		t.Errorf("Expected counter in body, got:\n%s", rec.Body.String())
+	}
+}
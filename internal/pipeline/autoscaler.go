@@ -0,0 +1,217 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"concurrent-pipeline-processor/internal/logger"
+)
+
+var (
+	// ErrInvalidAutoscaleBounds is returned when the autoscaler worker bounds are invalid
+	ErrInvalidAutoscaleBounds = errors.New("autoscale bounds must satisfy 0 < min workers <= max workers")
+	// ErrInvalidAutoscaleThresholds is returned when the autoscaler queue thresholds are invalid
+	ErrInvalidAutoscaleThresholds = errors.New("autoscale thresholds must satisfy 0 <= scale down ratio < scale up ratio <= 1")
+)
+
+const (
+	defaultAutoscaleInterval       = time.Second
+	defaultAutoscaleCooldown       = 5 * time.Second
+	defaultAutoscaleStep           = 1
+	defaultAutoscaleScaleUpRatio   = 0.75
+	defaultAutoscaleScaleDownRatio = 0.25
+)
+
+// AutoscaleOptions configures automatic resizing of the processor worker pool.
+// Zero values for Interval, Cooldown, Step and both ratios select defaults.
+type AutoscaleOptions struct {
+	// MinWorkers is the lower bound for the worker pool size
+	MinWorkers int
+	// MaxWorkers is the upper bound for the worker pool size
+	MaxWorkers int
+	// Interval specifies how often queue depth and latency are sampled
+	Interval time.Duration
+	// Cooldown specifies the minimum time between two scaling actions
+	Cooldown time.Duration
+	// Step specifies how many workers are added or removed per scaling action
+	Step int
+	// ScaleUpQueueRatio is the fill ratio of the processing backlog above which workers are added
+	ScaleUpQueueRatio float64
+	// ScaleDownQueueRatio is the fill ratio of the processing backlog below which workers are removed
+	ScaleDownQueueRatio float64
+	// TargetLatency is the average per-task processing latency above which
+	// workers are added while tasks are queued; zero ignores latency
+	TargetLatency time.Duration
+}
+
+// Validate checks if the autoscale options are valid
+func (o AutoscaleOptions) Validate() error {
+	if o.MinWorkers <= 0 || o.MaxWorkers < o.MinWorkers {
+		return ErrInvalidAutoscaleBounds
+	}
+	o = o.withDefaults()
+	if o.ScaleDownQueueRatio < 0 || o.ScaleUpQueueRatio > 1 || o.ScaleDownQueueRatio >= o.ScaleUpQueueRatio {
+		return ErrInvalidAutoscaleThresholds
+	}
+	return nil
+}
+
+func (o AutoscaleOptions) withDefaults() AutoscaleOptions {
+	if o.Interval <= 0 {
+		o.Interval = defaultAutoscaleInterval
+	}
+	if o.Cooldown <= 0 {
+		o.Cooldown = defaultAutoscaleCooldown
+	}
+	if o.Step <= 0 {
+		o.Step = defaultAutoscaleStep
+	}
+	if o.ScaleUpQueueRatio == 0 {
+		o.ScaleUpQueueRatio = defaultAutoscaleScaleUpRatio
+	}
+	if o.ScaleDownQueueRatio == 0 {
+		o.ScaleDownQueueRatio = defaultAutoscaleScaleDownRatio
+	}
+	return o
+}
+
+// latencyTracker accumulates processing latency between autoscaler samples
+type latencyTracker struct {
+	total atomic.Int64
+	count atomic.Int64
+}
+
+func (l *latencyTracker) observe(d time.Duration) {
+	l.total.Add(int64(d))
+	l.count.Add(1)
+}
+
+// take returns the average latency since the previous call and resets the tracker
+func (l *latencyTracker) take() time.Duration {
+	count := l.count.Swap(0)
+	total := l.total.Swap(0)
+	if count == 0 {
+		return 0
+	}
+	return time.Duration(total / count)
+}
+
+// autoscaleSample is a single observation of the processor stage
+type autoscaleSample struct {
+	workers  int
+	queued   int
+	capacity int
+	latency  time.Duration
+}
+
+func (s autoscaleSample) queueRatio() float64 {
+	if s.capacity == 0 {
+		return 0
+	}
+	return float64(s.queued) / float64(s.capacity)
+}
+
+// autoscaler decides the worker pool size from periodic samples
+type autoscaler struct {
+	opts       AutoscaleOptions
+	lastChange time.Time
+}
+
+func newAutoscaler(opts AutoscaleOptions) *autoscaler {
+	return &autoscaler{opts: opts.withDefaults()}
+}
+
+// decide returns the desired worker count for the sample and a short reason.
+// The gap between the scale up and scale down ratios provides hysteresis, and
+// no change is proposed until the cooldown since the last change has elapsed.
+func (a *autoscaler) decide(s autoscaleSample, now time.Time) (target int, reason string) {
+	target = clamp(s.workers, a.opts.MinWorkers, a.opts.MaxWorkers)
+	if target != s.workers {
+		return target, "out of bounds"
+	}
+	if !a.lastChange.IsZero() && now.Sub(a.lastChange) < a.opts.Cooldown {
+		return s.workers, ""
+	}
+
+	ratio := s.queueRatio()
+	slow := a.opts.TargetLatency > 0 && s.latency > a.opts.TargetLatency
+
+	switch {
+	case ratio >= a.opts.ScaleUpQueueRatio:
+		target, reason = s.workers+a.opts.Step, "queue depth high"
+	case slow && ratio > a.opts.ScaleDownQueueRatio:
+		target, reason = s.workers+a.opts.Step, "latency above target"
+	case ratio <= a.opts.ScaleDownQueueRatio && !slow:
+		target, reason = s.workers-a.opts.Step, "queue depth low"
+	}
+
+	return clamp(target, a.opts.MinWorkers, a.opts.MaxWorkers), reason
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// runAutoscaler periodically samples the processor stage and resizes the
+// worker pool until the context is cancelled or the pipeline has stopped
+func (p *pipeline) runAutoscaler(ctx context.Context, opts AutoscaleOptions) {
+	a := newAutoscaler(opts)
+	ticker := time.NewTicker(a.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			// A paused pipeline's queue grows without reflecting load
+			if p.pool.Paused() {
+				continue
+			}
+			// Tasks wait for a worker in their lanes as well as after
+			// validation; intake starts failing once a lane is full
+			queued, capacity := p.backlog()
+			for _, lane := range p.lanes {
+				queued += len(lane)
+			}
+			capacity += p.opts.InputBufferSize
+			s := autoscaleSample{
+				workers:  p.Workers(),
+				queued:   queued,
+				capacity: capacity,
+				latency:  p.latency.take(),
+			}
+			if s.workers == 0 {
+				continue
+			}
+
+			target, reason := a.decide(s, now)
+			if target == s.workers {
+				continue
+			}
+			if err := p.SetWorkers(target); err != nil {
+				return
+			}
+			a.lastChange = now
+
+			logger.Info().
+				Int("from_workers", s.workers).
+				Int("to_workers", target).
+				Int("queued", s.queued).
+				Float64("queue_ratio", s.queueRatio()).
+				Dur("avg_latency", s.latency).
+				Str("reason", reason).
+				Msg("Autoscaler resized worker pool")
+		}
+	}
+}
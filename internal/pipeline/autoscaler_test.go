@@ -0,0 +1,240 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestAutoscaleOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    AutoscaleOptions
+		wantErr error
+	}{
+		{
+			name: "valid bounds with defaults",
+			opts: AutoscaleOptions{MinWorkers: 1, MaxWorkers: 4},
+		},
+		{
+			name:    "zero min workers",
+			opts:    AutoscaleOptions{MinWorkers: 0, MaxWorkers: 4},
+			wantErr: ErrInvalidAutoscaleBounds,
+		},
+		{
+			name:    "max below min",
+			opts:    AutoscaleOptions{MinWorkers: 4, MaxWorkers: 2},
+			wantErr: ErrInvalidAutoscaleBounds,
+		},
+		{
+			name: "inverted thresholds",
+			opts: AutoscaleOptions{
+				MinWorkers:          1,
+				MaxWorkers:          4,
+				ScaleUpQueueRatio:   0.2,
+				ScaleDownQueueRatio: 0.5,
+			},
+			wantErr: ErrInvalidAutoscaleThresholds,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); err != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAutoscalerDecide(t *testing.T) {
+	opts := AutoscaleOptions{
+		MinWorkers:          2,
+		MaxWorkers:          6,
+		Cooldown:            time.Second,
+		Step:                2,
+		ScaleUpQueueRatio:   0.8,
+		ScaleDownQueueRatio: 0.2,
+		TargetLatency:       10 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name   string
+		sample autoscaleSample
+		want   int
+	}{
+		{
+			name:   "scales up on deep queue",
+			sample: autoscaleSample{workers: 2, queued: 90, capacity: 100},
+			want:   4,
+		},
+		{
+			name:   "caps at max workers",
+			sample: autoscaleSample{workers: 5, queued: 90, capacity: 100},
+			want:   6,
+		},
+		{
+			name:   "scales up on slow tasks with backlog",
+			sample: autoscaleSample{workers: 2, queued: 50, capacity: 100, latency: 20 * time.Millisecond},
+			want:   4,
+		},
+		{
+			name:   "holds inside hysteresis band",
+			sample: autoscaleSample{workers: 4, queued: 50, capacity: 100},
+			want:   4,
+		},
+		{
+			name:   "scales down on empty queue",
+			sample: autoscaleSample{workers: 6, queued: 0, capacity: 100},
+			want:   4,
+		},
+		{
+			name:   "does not scale down while slow",
+			sample: autoscaleSample{workers: 6, queued: 0, capacity: 100, latency: 20 * time.Millisecond},
+			want:   6,
+		},
+		{
+			name:   "floors at min workers",
+			sample: autoscaleSample{workers: 3, queued: 0, capacity: 100},
+			want:   2,
+		},
+		{
+			name:   "clamps out of bounds pool",
+			sample: autoscaleSample{workers: 10, queued: 50, capacity: 100},
+			want:   6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAutoscaler(opts)
+			if got, _ := a.decide(tt.sample, time.Now()); got != tt.want {
+				t.Errorf("decide() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("respects cooldown", func(t *testing.T) {
+		a := newAutoscaler(opts)
+		now := time.Now()
+		a.lastChange = now.Add(-500 * time.Millisecond)
+
+		sample := autoscaleSample{workers: 2, queued: 90, capacity: 100}
+		if got, _ := a.decide(sample, now); got != 2 {
+			t.Errorf("Expected no change during cooldown, got %d", got)
+		}
+		if got, _ := a.decide(sample, now.Add(time.Second)); got != 4 {
+			t.Errorf("Expected scale up after cooldown, got %d", got)
+		}
+	})
+}
+
+func TestAutoscaler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const numTasks = 40
+
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: numTasks,
+		TasksPerSecond:    1000,
+		BurstSize:         1000,
+		InputBufferSize:   numTasks,
+		ResultBufferSize:  numTasks,
+		Autoscale: &AutoscaleOptions{
+			MinWorkers: 1,
+			MaxWorkers: 4,
+			Interval:   10 * time.Millisecond,
+			Cooldown:   10 * time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+
+	impl := p.(*pipeline)
+	release := make(chan struct{})
+	impl.process = func(task models.Task) models.Result {
+		<-release
+		return processor.ProcessTask(task)
+	}
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	for i := 0; i < numTasks; i++ {
+		if err := p.AddTask(models.Task{Value: 1, Operations: []models.Operation{}}); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Workers() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected pool to grow to 4 workers, got %d", p.Workers())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	close(release)
+
+	select {
+	case result := <-p.Results():
+		if result.Result != numTasks {
+			t.Errorf("Expected sum %d, got %d", numTasks, result.Result)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for aggregated result")
+	}
+
+	deadline = time.Now().Add(2 * time.Second)
+	for p.Workers() > 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected pool to shrink to 1 worker, got %d", p.Workers())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAutoscalerStopsAfterDrain(t *testing.T) {
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: 1,
+		TasksPerSecond:    100,
+		Autoscale: &AutoscaleOptions{
+			MinWorkers: 1,
+			MaxWorkers: 2,
+			Interval:   time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+
+	// The context is never cancelled, so only the drain can stop the autoscaler
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatalf("Failed to drain pipeline: %v", err)
+	}
+
+	running := func() bool {
+		buf := make([]byte, 1<<20)
+		return bytes.Contains(buf[:runtime.Stack(buf, true)], []byte("(*pipeline).runAutoscaler"))
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for running() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the autoscaler to stop after the pipeline drained")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
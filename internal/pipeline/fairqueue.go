@@ -0,0 +1,229 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// ErrInvalidTenantWeight is returned when a fair queue tenant weight is invalid
+var ErrInvalidTenantWeight = errors.New("tenant weight must be greater than 0")
+
+const defaultTenantWeight = 1
+
+// FairQueueOptions configures deficit round robin scheduling across tenants
+// between the validator and the processor workers
+type FairQueueOptions struct {
+	// Weights maps tenant names to the number of tasks served per round
+	Weights map[string]int
+	// DefaultWeight is the weight of tenants without an entry in Weights; zero selects 1
+	DefaultWeight int
+	// Capacity bounds the number of tasks held by the scheduler; zero selects InputBufferSize
+	Capacity int
+}
+
+// Validate checks if the fair queue options are valid
+func (o FairQueueOptions) Validate() error {
+	if o.DefaultWeight < 0 {
+		return ErrInvalidTenantWeight
+	}
+	for tenant, w := range o.Weights {
+		if w <= 0 {
+			return fmt.Errorf("tenant %q: %w", tenant, ErrInvalidTenantWeight)
+		}
+	}
+	return nil
+}
+
+// tenantQueue holds the pending tasks of one tenant, one queue per priority.
+// The tenant's turns go to its priorities in the weighted order the priority
+// lanes are served in, so the fair queue keeps the order set by the lanes.
+type tenantQueue struct {
+	tenant  string
+	lanes   [models.PriorityTotalAmount][]job
+	sched   *laneScheduler
+	size    int
+	deficit int
+	// lane is the priority the head task is taken from, or -1 until chosen
+	lane int
+}
+
+// depths returns the number of tasks queued for each priority
+func (q *tenantQueue) depths() [models.PriorityTotalAmount]int {
+	var depths [models.PriorityTotalAmount]int
+	for i, lane := range q.lanes {
+		depths[i] = len(lane)
+	}
+	return depths
+}
+
+// head returns the lane of the next task, choosing it once per task so that
+// peeking does not advance the scheduler
+func (q *tenantQueue) head() int {
+	if q.lane < 0 {
+		q.lane = q.sched.next(q.depths())
+	}
+	return q.lane
+}
+
+// fairQueue is a deficit round robin queue keyed by tenant. Each task costs
+// one unit, so a tenant with weight w is served up to w tasks per round. It
+// is not safe for concurrent use.
+type fairQueue struct {
+	weights       map[string]int
+	defaultWeight int
+	laneWeights   map[models.Priority]int
+	maxLaneSkips  int
+	queues        map[string]*tenantQueue
+	active        []*tenantQueue
+	cursor        int
+	size          int
+}
+
+// newFairQueue creates a fair queue whose tenants serve their priorities
+// with the given lane weights and maximum lane skips, as the lanes do
+func newFairQueue(opts FairQueueOptions, laneWeights map[models.Priority]int, maxLaneSkips int) *fairQueue {
+	fq := &fairQueue{
+		weights:       opts.Weights,
+		defaultWeight: opts.DefaultWeight,
+		laneWeights:   laneWeights,
+		maxLaneSkips:  maxLaneSkips,
+		queues:        make(map[string]*tenantQueue),
+	}
+	if fq.defaultWeight <= 0 {
+		fq.defaultWeight = defaultTenantWeight
+	}
+	return fq
+}
+
+func (fq *fairQueue) weight(tenant string) int {
+	if w, ok := fq.weights[tenant]; ok {
+		return w
+	}
+	return fq.defaultWeight
+}
+
+func (fq *fairQueue) len() int {
+	return fq.size
+}
+
+func (fq *fairQueue) push(j job) {
+	q, ok := fq.queues[j.task.Tenant]
+	if !ok {
+		q = &tenantQueue{
+			tenant: j.task.Tenant,
+			sched:  newLaneScheduler(fq.laneWeights, fq.maxLaneSkips),
+			lane:   -1,
+		}
+		fq.queues[j.task.Tenant] = q
+		fq.active = append(fq.active, q)
+	}
+	q.lanes[j.task.Priority] = append(q.lanes[j.task.Priority], j)
+	q.size++
+	fq.size++
+}
+
+// peek returns the task pop would return without removing it
+func (fq *fairQueue) peek() (job, bool) {
+	if fq.size == 0 {
+		return job{}, false
+	}
+	q := fq.active[fq.cursor]
+	return q.lanes[q.head()][0], true
+}
+
+// pop removes and returns the next task in deficit round robin order
+func (fq *fairQueue) pop() (job, bool) {
+	if fq.size == 0 {
+		return job{}, false
+	}
+
+	q := fq.active[fq.cursor]
+	if q.deficit == 0 {
+		q.deficit = fq.weight(q.tenant)
+	}
+
+	lane := q.head()
+	j := q.lanes[lane][0]
+	q.lanes[lane][0] = job{}
+	q.lanes[lane] = q.lanes[lane][1:]
+	q.lane = -1
+	q.size--
+	q.deficit--
+	fq.size--
+
+	switch {
+	case q.size == 0:
+		// Idle tenants leave the round and lose their remaining deficit
+		delete(fq.queues, q.tenant)
+		fq.active = append(fq.active[:fq.cursor], fq.active[fq.cursor+1:]...)
+	case q.deficit == 0:
+		fq.cursor++
+	}
+	if fq.cursor >= len(fq.active) {
+		fq.cursor = 0
+	}
+
+	return j, true
+}
+
+// runFairQueue moves validated tasks into the fair queue and hands them to
+// the workers in deficit round robin order until the validated channel is
+// closed and the queue is drained
+func (p *pipeline) runFairQueue(ctx context.Context, opts FairQueueOptions) {
+	defer func() {
+		close(p.work)
+		p.wg.Done()
+	}()
+
+	fq := newFairQueue(opts, p.opts.LaneWeights, p.opts.MaxLaneSkips)
+	capacity := opts.Capacity
+	if capacity <= 0 {
+		capacity = p.opts.InputBufferSize
+	}
+
+	in := p.validated
+	for {
+		next, ok := fq.peek()
+		if !ok {
+			if in == nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case j, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				fq.push(j)
+				p.fairQueued.Add(1)
+			}
+			continue
+		}
+
+		// Stop reading validated tasks while full so the validator blocks
+		recv := in
+		if fq.len() >= capacity {
+			recv = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case p.work <- next:
+			fq.pop()
+			p.fairQueued.Add(-1)
+		case j, ok := <-recv:
+			if !ok {
+				in = nil
+				continue
+			}
+			fq.push(j)
+			p.fairQueued.Add(1)
+		}
+	}
+}
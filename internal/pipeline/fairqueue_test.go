@@ -0,0 +1,259 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestFairQueue(t *testing.T) {
+	drain := func(fq *fairQueue) string {
+		var order []string
+		for {
+			task, ok := fq.pop()
+			if !ok {
+				return strings.Join(order, "")
+			}
+			order = append(order, task.task.Tenant)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		weights map[string]int
+		pushes  string
+		want    string
+	}{
+		{
+			name:   "round robin with equal weights",
+			pushes: "aaaaaabb",
+			want:   "ababaaaa",
+		},
+		{
+			name:    "weighted round robin",
+			weights: map[string]int{"a": 2},
+			pushes:  "aaaaaabbbb",
+			want:    "aabaabaabb",
+		},
+		{
+			name:   "single tenant keeps fifo order",
+			pushes: "aaa",
+			want:   "aaa",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fq := newFairQueue(FairQueueOptions{Weights: tt.weights}, nil, 0)
+			for _, tenant := range tt.pushes {
+				fq.push(job{task: models.Task{Tenant: string(tenant)}})
+			}
+			if got := drain(fq); got != tt.want {
+				t.Errorf("Expected order %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("serves a tenant's priorities in lane order", func(t *testing.T) {
+		fq := newFairQueue(FairQueueOptions{}, nil, 0)
+		for _, prio := range []models.Priority{models.PriorityLow, models.PriorityLow, models.PriorityHigh, models.PriorityNormal} {
+			fq.push(job{task: models.Task{Tenant: "a", Priority: prio}})
+		}
+		var got []models.Priority
+		for fq.len() > 0 {
+			j, _ := fq.pop()
+			got = append(got, j.task.Priority)
+		}
+		want := []models.Priority{models.PriorityHigh, models.PriorityNormal, models.PriorityLow, models.PriorityLow}
+		if !slices.Equal(got, want) {
+			t.Errorf("Expected order %v, got %v", want, got)
+		}
+	})
+
+	t.Run("peek matches pop", func(t *testing.T) {
+		fq := newFairQueue(FairQueueOptions{}, nil, 0)
+		for i, tenant := range "aabba" {
+			fq.push(job{task: models.Task{Tenant: string(tenant), Value: i}})
+		}
+		for fq.len() > 0 {
+			peeked, _ := fq.peek()
+			popped, _ := fq.pop()
+			if peeked.task.Value != popped.task.Value {
+				t.Fatalf("peek returned task %d, pop returned %d", peeked.task.Value, popped.task.Value)
+			}
+		}
+	})
+}
+
+func TestFairQueueOptionsValidate(t *testing.T) {
+	opts := FairQueueOptions{Weights: map[string]int{"a": 0}}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidTenantWeight) {
+		t.Errorf("Expected ErrInvalidTenantWeight, got %v", err)
+	}
+	if err := (FairQueueOptions{DefaultWeight: -1}).Validate(); err != ErrInvalidTenantWeight {
+		t.Errorf("Expected ErrInvalidTenantWeight, got %v", err)
+	}
+}
+
+func TestFairQueueScheduling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		numBulk  = 10
+		numSmall = 2
+	)
+
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: numBulk + numSmall,
+		TasksPerSecond:    100,
+		BurstSize:         200,
+		InputBufferSize:   50,
+		ResultBufferSize:  50,
+		FairQueue:         &FairQueueOptions{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+
+	var (
+		mu      sync.Mutex
+		order   []string
+		release = make(chan struct{})
+	)
+	p.(*pipeline).process = func(task models.Task) models.Result {
+		<-release
+		mu.Lock()
+		order = append(order, task.Tenant)
+		mu.Unlock()
+		return processor.ProcessTask(task)
+	}
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	for i := 0; i < numBulk; i++ {
+		if err := p.AddTask(models.Task{Value: 1, Operations: []models.Operation{}, Tenant: "bulk"}); err != nil {
+			t.Fatalf("Failed to add bulk task: %v", err)
+		}
+	}
+	for i := 0; i < numSmall; i++ {
+		if err := p.AddTask(models.Task{Value: 1, Operations: []models.Operation{}, Tenant: "small"}); err != nil {
+			t.Fatalf("Failed to add small task: %v", err)
+		}
+	}
+
+	// Let every task reach the fair queue before the worker starts
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case result := <-p.Results():
+		if result.Result != numBulk+numSmall {
+			t.Errorf("Expected sum %d, got %d", numBulk+numSmall, result.Result)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for aggregated result")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	lastSmall := -1
+	for i, tenant := range order {
+		if tenant == "small" {
+			lastSmall = i
+		}
+	}
+	// The worker holds one bulk task and the scheduler one more; after that
+	// the tenants alternate, so both small tasks finish within six slots
+	if lastSmall < 0 || lastSmall > 5 {
+		t.Errorf("Expected small tenant to be served early, got order %v", order)
+	}
+}
+
+func TestFairQueueKeepsLanePriority(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		numLow  = 20
+		numHigh = 3
+	)
+
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: numLow + numHigh,
+		TasksPerSecond:    100,
+		BurstSize:         200,
+		InputBufferSize:   50,
+		ResultBufferSize:  50,
+		FairQueue:         &FairQueueOptions{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+
+	var (
+		mu      sync.Mutex
+		order   []models.Priority
+		release = make(chan struct{})
+	)
+	p.(*pipeline).process = func(task models.Task) models.Result {
+		<-release
+		mu.Lock()
+		order = append(order, task.Priority)
+		mu.Unlock()
+		return processor.ProcessTask(task)
+	}
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	// The low tasks reach the fair queue while the worker is held, then the
+	// same tenant sends high priority tasks
+	for i := 0; i < numLow; i++ {
+		if err := p.AddTask(models.Task{Value: 1, Operations: []models.Operation{}, Priority: models.PriorityLow}); err != nil {
+			t.Fatalf("Failed to add low task: %v", err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < numHigh; i++ {
+		if err := p.AddTask(models.Task{Value: 1, Operations: []models.Operation{}, Priority: models.PriorityHigh}); err != nil {
+			t.Fatalf("Failed to add high task: %v", err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case <-p.Results():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for aggregated result")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	lastHigh := -1
+	for i, prio := range order {
+		if prio == models.PriorityHigh {
+			lastHigh = i
+		}
+	}
+	// The worker holds one low task and the fair queue is handing it
+	// another; the high tasks come right after them
+	if lastHigh < 0 || lastHigh > numHigh+1 {
+		t.Errorf("Expected high priority tasks among the first %d processed, got order %v", numHigh+2, order)
+	}
+}
@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// ValidateFunc validates a task, returning the task to process
+type ValidateFunc func(ctx context.Context, task models.Task) (models.Task, error)
+
+// ProcessFunc processes a task
+type ProcessFunc func(ctx context.Context, task models.Task) models.Result
+
+// AggregateFunc adds a processed result to the current aggregation window
+type AggregateFunc func(ctx context.Context, result models.Result)
+
+// ValidatorInterceptor wraps task validation. It may change the task before
+// calling next or the task next returns, or reject the task by returning an
+// error without calling next.
+type ValidatorInterceptor func(ctx context.Context, task models.Task, next ValidateFunc) (models.Task, error)
+
+// ProcessorInterceptor wraps task processing. It may change the task before
+// calling next or the result after, or return a result without calling next.
+type ProcessorInterceptor func(ctx context.Context, task models.Task, next ProcessFunc) models.Result
+
+// AggregatorInterceptor wraps the aggregation of a processed result. It may
+// change the result before calling next, or drop it by not calling next.
+type AggregatorInterceptor func(ctx context.Context, result models.Result, next AggregateFunc)
+
+// Interceptors holds the interceptor chain of each stage. Interceptors run in
+// slice order: the first is outermost, seeing an item first and the outcome
+// of next last. The ctx they receive carries the stage's span, if tracing.
+type Interceptors struct {
+	Validator  []ValidatorInterceptor
+	Processor  []ProcessorInterceptor
+	Aggregator []AggregatorInterceptor
+}
+
+// chainValidator wraps final in the interceptors
+func chainValidator(final ValidateFunc, interceptors []ValidatorInterceptor) ValidateFunc {
+	next := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		ic, inner := interceptors[i], next
+		next = func(ctx context.Context, task models.Task) (models.Task, error) {
+			return ic(ctx, task, inner)
+		}
+	}
+	return next
+}
+
+// chainProcessor wraps final in the interceptors
+func chainProcessor(final ProcessFunc, interceptors []ProcessorInterceptor) ProcessFunc {
+	next := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		ic, inner := interceptors[i], next
+		next = func(ctx context.Context, task models.Task) models.Result {
+			return ic(ctx, task, inner)
+		}
+	}
+	return next
+}
+
+// chainAggregator wraps final in the interceptors
+func chainAggregator(final AggregateFunc, interceptors []AggregatorInterceptor) AggregateFunc {
+	next := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		ic, inner := interceptors[i], next
+		next = func(ctx context.Context, result models.Result) {
+			ic(ctx, result, inner)
+		}
+	}
+	return next
+}
@@ -0,0 +1,209 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/tracing"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestInterceptorOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) ProcessorInterceptor {
+		return func(ctx context.Context, task models.Task, next ProcessFunc) models.Result {
+			calls = append(calls, name+" before")
+			result := next(ctx, task)
+			calls = append(calls, name+" after")
+			return result
+		}
+	}
+
+	process := chainProcessor(func(context.Context, models.Task) models.Result {
+		calls = append(calls, "stage")
+		return models.Result{}
+	}, []ProcessorInterceptor{record("first"), record("second")})
+	process(context.Background(), models.Task{})
+
+	want := []string{"first before", "second before", "stage", "second after", "first after"}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+}
+
+// runWithInterceptors drains tasks through a pipeline using the given
+// interceptors and returns the results, sorted by value
+func runWithInterceptors(t *testing.T, ics Interceptors, tasks ...models.Task) []models.Result {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	p, err := NewPipeline(Options{
+		NumWorkers:        2,
+		AggregationWindow: 1,
+		TasksPerSecond:    100,
+		BurstSize:         200,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+		Interceptors:      ics,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	for _, task := range tasks {
+		if err := p.AddTask(task); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := p.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	var results []models.Result
+	for result := range p.Results() {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Result < results[j].Result })
+	return results
+}
+
+func TestPipelineInterceptors(t *testing.T) {
+	errBlocked := errors.New("tenant blocked")
+	task := func(value int, tenant string) models.Task {
+		return models.Task{Value: value, Operations: []models.Operation{}, Tenant: tenant}
+	}
+
+	t.Run("validator short-circuits and enriches", func(t *testing.T) {
+		results := runWithInterceptors(t, Interceptors{
+			Validator: []ValidatorInterceptor{
+				func(ctx context.Context, task models.Task, next ValidateFunc) (models.Task, error) {
+					if task.Tenant == "blocked" {
+						return task, errBlocked
+					}
+					return next(ctx, task)
+				},
+				func(ctx context.Context, task models.Task, next ValidateFunc) (models.Task, error) {
+					task.Value += 10
+					return next(ctx, task)
+				},
+			},
+		}, task(1, "alpha"), task(2, "blocked"))
+
+		if len(results) != 2 {
+			t.Fatalf("Expected 2 results, got %v", results)
+		}
+		if !errors.Is(results[0].Error, errBlocked) {
+			t.Errorf("Expected blocked task to fail with errBlocked, got %v", results[0].Error)
+		}
+		if results[1].Result != 11 {
+			t.Errorf("Expected enriched task to produce 11, got %d", results[1].Result)
+		}
+	})
+
+	t.Run("processor short-circuits and modifies results", func(t *testing.T) {
+		results := runWithInterceptors(t, Interceptors{
+			Processor: []ProcessorInterceptor{
+				func(ctx context.Context, task models.Task, next ProcessFunc) models.Result {
+					if task.Value == 42 {
+						return models.Result{Result: 1000}
+					}
+					result := next(ctx, task)
+					result.Result *= 2
+					return result
+				},
+			},
+		}, task(3, ""), task(42, ""))
+
+		if len(results) != 2 || results[0].Result != 6 || results[1].Result != 1000 {
+			t.Errorf("Expected results 6 and 1000, got %v", results)
+		}
+	})
+
+	t.Run("aggregator drops and modifies results", func(t *testing.T) {
+		results := runWithInterceptors(t, Interceptors{
+			Aggregator: []AggregatorInterceptor{
+				func(ctx context.Context, result models.Result, next AggregateFunc) {
+					if result.Result == 5 {
+						return
+					}
+					result.Result = -result.Result
+					next(ctx, result)
+				},
+			},
+		}, task(4, ""), task(5, ""))
+
+		if len(results) != 1 || results[0].Result != -4 {
+			t.Errorf("Expected only result -4, got %v", results)
+		}
+	})
+
+	t.Run("interceptor panics become error results", func(t *testing.T) {
+		results := runWithInterceptors(t, Interceptors{
+			Processor: []ProcessorInterceptor{
+				func(context.Context, models.Task, ProcessFunc) models.Result {
+					panic("interceptor failed")
+				},
+			},
+		}, task(1, ""))
+
+		if len(results) != 1 || !errors.Is(results[0].Error, ErrStagePanic) {
+			t.Errorf("Expected a panic error result, got %v", results)
+		}
+	})
+}
+
+func TestInterceptorSpanContext(t *testing.T) {
+	rec := &spanRecorder{}
+	tracer := tracing.NewTracer(rec, nil)
+
+	seen := make(chan tracing.SpanContext, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: 1,
+		TasksPerSecond:    100,
+		BurstSize:         200,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+		Tracer:            tracer,
+		Interceptors: Interceptors{
+			Processor: []ProcessorInterceptor{
+				func(ctx context.Context, task models.Task, next ProcessFunc) models.Result {
+					seen <- tracing.SpanContextFromContext(ctx)
+					return next(ctx, task)
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	if err := p.AddTask(models.Task{Value: 1, Operations: []models.Operation{}}); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := p.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	sc := <-seen
+	spans := rec.byName()[spanProcess]
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 process span, got %d", len(spans))
+	}
+	if sc != spans[0].SpanContext {
+		t.Errorf("Expected interceptor context to carry the process span %v, got %v", spans[0].SpanContext, sc)
+	}
+}
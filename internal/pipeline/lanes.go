@@ -0,0 +1,171 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+var (
+	// ErrBufferFull is returned when the task's priority lane is full
+	ErrBufferFull = models.NewError(models.CodeBufferFull, "pipeline buffer full")
+
+	// ErrInvalidPriority is returned when a task has an unknown priority
+	ErrInvalidPriority = models.NewError(models.CodeInvalidPriority, "invalid task priority")
+	// ErrInvalidLaneWeight is returned when a priority lane weight is negative
+	ErrInvalidLaneWeight = errors.New("lane weight must not be negative")
+)
+
+const defaultMaxLaneSkips = 32
+
+// defaultLaneWeights are used for lanes without a configured weight
+var defaultLaneWeights = map[models.Priority]int{
+	models.PriorityHigh:   8,
+	models.PriorityNormal: 4,
+	models.PriorityLow:    1,
+}
+
+// laneScheduler picks the next lane to serve using smooth weighted round
+// robin over the non-empty lanes. A non-empty lane that has been passed over
+// maxSkips times in a row is served next regardless of its weight.
+type laneScheduler struct {
+	weights  [models.PriorityTotalAmount]int
+	current  [models.PriorityTotalAmount]int
+	skips    [models.PriorityTotalAmount]int
+	maxSkips int
+}
+
+func newLaneScheduler(weights map[models.Priority]int, maxSkips int) *laneScheduler {
+	ls := &laneScheduler{maxSkips: maxSkips}
+	if ls.maxSkips <= 0 {
+		ls.maxSkips = defaultMaxLaneSkips
+	}
+	for i := range ls.weights {
+		prio := models.Priority(i)
+		w, ok := weights[prio]
+		if !ok || w == 0 {
+			w = defaultLaneWeights[prio]
+		}
+		ls.weights[i] = w
+	}
+	return ls
+}
+
+// next returns the lane to serve given the current lane depths, or -1 when
+// every lane is empty
+func (ls *laneScheduler) next(depths [models.PriorityTotalAmount]int) int {
+	pick, total := -1, 0
+
+	// Starvation protection takes precedence over weights
+	for i, depth := range depths {
+		if depth > 0 && ls.skips[i] >= ls.maxSkips && (pick < 0 || ls.skips[i] > ls.skips[pick]) {
+			pick = i
+		}
+	}
+
+	if pick < 0 {
+		for i, depth := range depths {
+			if depth == 0 {
+				continue
+			}
+			ls.current[i] += ls.weights[i]
+			total += ls.weights[i]
+			if pick < 0 || ls.current[i] > ls.current[pick] {
+				pick = i
+			}
+		}
+		if pick < 0 {
+			return -1
+		}
+		ls.current[pick] -= total
+	}
+
+	for i, depth := range depths {
+		switch {
+		case i == pick || depth == 0:
+			ls.skips[i] = 0
+		default:
+			ls.skips[i]++
+		}
+	}
+	return pick
+}
+
+// enqueue places the task in its priority lane without blocking
+func (p *pipeline) enqueue(j job) error {
+	select {
+	case p.lanes[j.task.Priority] <- j:
+	default:
+		return ErrBufferFull
+	}
+
+	// Wake the scheduler; a pending signal already covers this task
+	select {
+	case p.laneReady <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (p *pipeline) LaneDepths() map[models.Priority]int {
+	depths := make(map[models.Priority]int, len(p.lanes))
+	for i, lane := range p.lanes {
+		depths[models.Priority(i)] = len(lane)
+	}
+	return depths
+}
+
+// laneDepths returns the number of tasks queued in every lane
+func (p *pipeline) laneDepths() [models.PriorityTotalAmount]int {
+	var depths [models.PriorityTotalAmount]int
+	for i, lane := range p.lanes {
+		depths[i] = len(lane)
+	}
+	return depths
+}
+
+// runLaneScheduler moves tasks from the priority lanes into the input channel
+// in weighted order until intake is closed and every lane is drained
+func (p *pipeline) runLaneScheduler(ctx context.Context) {
+	defer func() {
+		close(p.input)
+		p.wg.Done()
+	}()
+
+	sched := newLaneScheduler(p.opts.LaneWeights, p.opts.MaxLaneSkips)
+
+	for {
+		i := sched.next(p.laneDepths())
+		if i < 0 {
+			select {
+			case <-p.intakeClosed:
+				// A task may have been added after the depths were read but
+				// before intake closed; no task can be added any more, so
+				// return only once the lanes are empty after the close
+				if p.laneDepths() == ([models.PriorityTotalAmount]int{}) {
+					return
+				}
+				continue
+			default:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.laneReady:
+			case <-p.intakeClosed:
+			}
+			continue
+		}
+
+		// This goroutine is the only receiver, so a non-empty lane never blocks
+		j := <-p.lanes[i]
+
+		select {
+		case p.input <- j:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
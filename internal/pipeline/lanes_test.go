@@ -0,0 +1,263 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestLaneScheduler(t *testing.T) {
+	t.Run("returns -1 when all lanes are empty", func(t *testing.T) {
+		ls := newLaneScheduler(nil, 0)
+		if got := ls.next([models.PriorityTotalAmount]int{}); got != -1 {
+			t.Errorf("Expected -1, got %d", got)
+		}
+	})
+
+	t.Run("serves lanes in proportion to weights", func(t *testing.T) {
+		ls := newLaneScheduler(map[models.Priority]int{
+			models.PriorityHigh:   3,
+			models.PriorityNormal: 2,
+			models.PriorityLow:    1,
+		}, 100)
+
+		busy := [models.PriorityTotalAmount]int{1, 1, 1}
+		counts := map[models.Priority]int{}
+		for i := 0; i < 60; i++ {
+			counts[models.Priority(ls.next(busy))]++
+		}
+
+		if counts[models.PriorityHigh] != 30 {
+			t.Errorf("Expected 30 high picks, got %d", counts[models.PriorityHigh])
+		}
+		if counts[models.PriorityNormal] != 20 {
+			t.Errorf("Expected 20 normal picks, got %d", counts[models.PriorityNormal])
+		}
+		if counts[models.PriorityLow] != 10 {
+			t.Errorf("Expected 10 low picks, got %d", counts[models.PriorityLow])
+		}
+	})
+
+	t.Run("skips empty lanes", func(t *testing.T) {
+		ls := newLaneScheduler(nil, 0)
+		depths := [models.PriorityTotalAmount]int{}
+		depths[models.PriorityLow] = 1
+
+		for i := 0; i < 5; i++ {
+			if got := ls.next(depths); got != int(models.PriorityLow) {
+				t.Fatalf("Expected low lane, got %d", got)
+			}
+		}
+	})
+
+	t.Run("protects low priority lanes from starvation", func(t *testing.T) {
+		ls := newLaneScheduler(map[models.Priority]int{
+			models.PriorityHigh:   1000,
+			models.PriorityNormal: 1000,
+			models.PriorityLow:    1,
+		}, 4)
+
+		busy := [models.PriorityTotalAmount]int{1, 1, 1}
+		gap := 0
+		for i := 0; i < 50; i++ {
+			if ls.next(busy) == int(models.PriorityLow) {
+				gap = 0
+				continue
+			}
+			gap++
+			if gap > 4 {
+				t.Fatalf("Low lane passed over %d times in a row", gap)
+			}
+		}
+	})
+}
+
+func TestPriorityLanes(t *testing.T) {
+	t.Run("rejects unknown priority", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        1,
+			AggregationWindow: 1,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   10,
+			ResultBufferSize:  10,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		task := models.Task{Operations: []models.Operation{}, Priority: models.PriorityTotalAmount}
+		if err := p.AddTask(task); !errors.Is(err, ErrInvalidPriority) {
+			t.Errorf("Expected ErrInvalidPriority, got %v", err)
+		}
+	})
+
+	t.Run("high priority overtakes queued low priority tasks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		const numLow = 6
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        1,
+			AggregationWindow: numLow + 2,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   20,
+			ResultBufferSize:  20,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+
+		var (
+			mu      sync.Mutex
+			order   []models.Priority
+			release = make(chan struct{})
+			first   sync.Once
+		)
+		p.(*pipeline).validate = func(task models.Task) error {
+			first.Do(func() { <-release })
+			mu.Lock()
+			order = append(order, task.Priority)
+			mu.Unlock()
+			return nil
+		}
+
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		// The first task holds the validator while the lanes fill up
+		if err := p.AddTask(models.Task{Operations: []models.Operation{}, Priority: models.PriorityLow}); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		for i := 0; i < numLow; i++ {
+			if err := p.AddTask(models.Task{Operations: []models.Operation{}, Priority: models.PriorityLow}); err != nil {
+				t.Fatalf("Failed to add low task: %v", err)
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if err := p.AddTask(models.Task{Operations: []models.Operation{}, Priority: models.PriorityHigh}); err != nil {
+			t.Fatalf("Failed to add high task: %v", err)
+		}
+
+		if got := p.LaneDepths()[models.PriorityLow]; got < numLow-1 {
+			t.Errorf("Expected at least %d queued low tasks, got %d", numLow-1, got)
+		}
+		if got := p.LaneDepths()[models.PriorityHigh]; got != 1 {
+			t.Errorf("Expected 1 queued high task, got %d", got)
+		}
+
+		close(release)
+
+		select {
+		case <-p.Results():
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for aggregated result")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		highAt := -1
+		for i, prio := range order {
+			if prio == models.PriorityHigh {
+				highAt = i
+			}
+		}
+		// One low task is validating and at most one more is already held by
+		// the scheduler, so the high task must come right after them
+		if highAt < 0 || highAt > 2 {
+			t.Errorf("Expected high priority task among the first 3 validated, got position %d in %v", highAt, order)
+		}
+	})
+
+	t.Run("high priority overtakes tasks waiting for a busy worker", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		const numLow = 8
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        1,
+			AggregationWindow: numLow + 2,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   20,
+			ResultBufferSize:  20,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+
+		var (
+			mu      sync.Mutex
+			order   []models.Priority
+			release = make(chan struct{})
+			first   sync.Once
+		)
+		p.(*pipeline).process = func(task models.Task) models.Result {
+			first.Do(func() { <-release })
+			mu.Lock()
+			order = append(order, task.Priority)
+			mu.Unlock()
+			return models.Result{}
+		}
+
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		// The first task holds the only worker while the lanes fill up
+		if err := p.AddTask(models.Task{Operations: []models.Operation{}, Priority: models.PriorityLow}); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		for i := 0; i < numLow; i++ {
+			if err := p.AddTask(models.Task{Operations: []models.Operation{}, Priority: models.PriorityLow}); err != nil {
+				t.Fatalf("Failed to add low task: %v", err)
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if err := p.AddTask(models.Task{Operations: []models.Operation{}, Priority: models.PriorityHigh}); err != nil {
+			t.Fatalf("Failed to add high task: %v", err)
+		}
+
+		close(release)
+
+		select {
+		case <-p.Results():
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for aggregated result")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		highAt := -1
+		for i, prio := range order {
+			if prio == models.PriorityHigh {
+				highAt = i
+			}
+		}
+		// Besides the task being processed, the validator and the scheduler
+		// hold at most one low task each
+		if highAt < 0 || highAt > 3 {
+			t.Errorf("Expected high priority task among the first 4 processed, got position %d in %v", highAt, order)
+		}
+	})
+}
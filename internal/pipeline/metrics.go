@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"errors"
+	"time"
+
+	"concurrent-pipeline-processor/internal/metrics"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// stageBuckets cover per-task stage latencies from 10µs to roughly 2.6s
+var stageBuckets = metrics.ExponentialBuckets(0.00001, 4, 10)
+
+// pipelineMetrics holds the instruments updated by the pipeline stages
+type pipelineMetrics struct {
+	accepted      *metrics.Counter
+	rejected      *metrics.CounterVec
+	validated     *metrics.Counter
+	failed        *metrics.CounterVec
+	processed     *metrics.Counter
+	aggregated    *metrics.Counter
+	panics        *metrics.CounterVec
+	stageDuration *metrics.HistogramVec
+}
+
+// newPipelineMetrics registers the pipeline instruments and queue depth
+// gauges with reg
+func newPipelineMetrics(reg *metrics.Registry, p *pipeline) *pipelineMetrics {
+	m := &pipelineMetrics{
+		accepted: reg.NewCounter("pipeline_tasks_accepted_total",
+			"Tasks accepted by AddTask."),
+		rejected: reg.NewCounterVec("pipeline_tasks_rejected_total",
+			"Tasks rejected by AddTask, by reason.", "reason"),
+		validated: reg.NewCounter("pipeline_tasks_validated_total",
+			"Tasks that passed validation."),
+		failed: reg.NewCounterVec("pipeline_tasks_failed_total",
+			"Tasks that produced an error result, by stage.", "stage"),
+		processed: reg.NewCounter("pipeline_tasks_processed_total",
+			"Tasks processed successfully."),
+		aggregated: reg.NewCounter("pipeline_windows_aggregated_total",
+			"Aggregation windows emitted."),
+		panics: reg.NewCounterVec("pipeline_panics_total",
+			"Panics recovered in pipeline stages, by stage.", "stage"),
+		stageDuration: reg.NewHistogramVec("pipeline_stage_duration_seconds",
+			"Time spent handling a single item in each stage.", stageBuckets, "stage"),
+	}
+
+	depth := reg.NewGaugeFuncVec("pipeline_channel_depth",
+		"Items buffered in each pipeline channel.", "channel")
+	depth.Set(func() float64 {
+		queued := len(p.input)
+		for _, lane := range p.lanes {
+			queued += len(lane)
+		}
+		return float64(queued)
+	}, "input")
+	depth.Set(func() float64 {
+		queued, _ := p.backlog()
+		return float64(queued)
+	}, "validated")
+	depth.Set(func() float64 { return float64(p.processedDepth()) }, "processed")
+	depth.Set(func() float64 { return float64(len(p.output)) }, "output")
+
+	lanes := reg.NewGaugeFuncVec("pipeline_lane_depth",
+		"Tasks queued in each priority lane.", "priority")
+	for i := range p.lanes {
+		lane := p.lanes[i]
+		lanes.Set(func() float64 { return float64(len(lane)) }, models.Priority(i).String())
+	}
+
+	reg.NewGaugeFunc("pipeline_workers",
+		"Running processor workers.", func() float64 { return float64(p.Workers()) })
+
+	return m
+}
+
+// observeStage records how long a stage spent on one item
+func (m *pipelineMetrics) observeStage(stage string, start time.Time) {
+	m.stageDuration.With(stage).Observe(time.Since(start).Seconds())
+}
+
+// recordAdmission counts the outcome of an AddTask call
+func (m *pipelineMetrics) recordAdmission(err error) {
+	if err == nil {
+		m.accepted.Inc()
+		return
+	}
+	m.rejected.With(rejectReason(err)).Inc()
+}
+
+// rejectReasons lists every label rejectReason can return
+var rejectReasons = []string{
+	"tenant_rate_limit", "rate_limit", "buffer_full", "stopped", "not_started", "intake_paused",
+	"paused", "invalid_priority", "other",
+}
+
+// rejectReason maps an AddTask error to a metric label
+func rejectReason(err error) string {
+	switch {
+	case errors.Is(err, ErrTenantRateLimitExceeded):
+		return "tenant_rate_limit"
+	case errors.Is(err, ErrRateLimitExceeded):
+		return "rate_limit"
+	case errors.Is(err, ErrBufferFull):
+		return "buffer_full"
+	case errors.Is(err, ErrPipelineStopped):
+		return "stopped"
+	case errors.Is(err, ErrPipelineNotStarted):
+		return "not_started"
+	case errors.Is(err, ErrIntakePaused):
+		return "intake_paused"
+	case errors.Is(err, ErrPipelinePaused):
+		return "paused"
+	case errors.Is(err, ErrInvalidPriority):
+		return "invalid_priority"
+	default:
+		return "other"
+	}
+}
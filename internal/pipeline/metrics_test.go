@@ -0,0 +1,126 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/metrics"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestPipelineMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reg := metrics.NewRegistry()
+	p, err := NewPipeline(Options{
+		NumWorkers:        2,
+		AggregationWindow: 2,
+		TasksPerSecond:    3,
+		BurstSize:         3,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+		Metrics:           reg,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	impl := p.(*pipeline)
+
+	if err := p.AddTask(models.Task{}); !errors.Is(err, ErrPipelineNotStarted) {
+		t.Fatalf("Expected ErrPipelineNotStarted, got %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	valid := models.Task{Value: 2, Operations: []models.Operation{{Operator: models.OperatorPlus, Value: 3}}}
+	invalid := models.Task{Value: 2}
+
+	for _, task := range []models.Task{valid, valid, invalid} {
+		if err := p.AddTask(task); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := p.AddTask(valid); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+
+	// One window of two tasks and one validation error
+	for i := 0; i < 2; i++ {
+		select {
+		case <-p.Results():
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for results")
+		}
+	}
+
+	m := impl.metrics
+	checks := []struct {
+		name string
+		got  uint64
+		want uint64
+	}{
+		{"accepted", m.accepted.Value(), 3},
+		{"rejected rate limit", m.rejected.With("rate_limit").Value(), 1},
+		{"rejected not started", m.rejected.With("not_started").Value(), 1},
+		{"validated", m.validated.Value(), 2},
+		{"failed validator", m.failed.With(stageValidator).Value(), 1},
+		{"processed", m.processed.Value(), 2},
+		{"aggregated", m.aggregated.Value(), 1},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("Expected %s=%d, got %d", c.name, c.want, c.got)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := reg.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	text := buf.String()
+	for _, want := range []string{
+		`pipeline_tasks_accepted_total 3`,
+		`pipeline_tasks_rejected_total{reason="rate_limit"} 1`,
+		`pipeline_channel_depth{channel="input"} `,
+		`pipeline_channel_depth{channel="validated"} `,
+		`pipeline_channel_depth{channel="processed"} `,
+		`pipeline_channel_depth{channel="output"} `,
+		`pipeline_lane_depth{priority="high"} 0`,
+		`pipeline_stage_duration_seconds_count{stage="processor"} 2`,
+		`pipeline_workers 2`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected metrics output to contain %q", want)
+		}
+	}
+}
+
+func TestRejectReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{ErrTenantRateLimitExceeded, "tenant_rate_limit"},
+		{ErrRateLimitExceeded, "rate_limit"},
+		{ErrBufferFull, "buffer_full"},
+		{ErrPipelineStopped, "stopped"},
+		{ErrPipelineNotStarted, "not_started"},
+		{ErrIntakePaused, "intake_paused"},
+		{ErrPipelinePaused, "paused"},
+		{ErrInvalidPriority, "invalid_priority"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := rejectReason(tt.err); got != tt.want {
+				t.Errorf("rejectReason(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,34 +2,66 @@ package pipeline
 
 import (
 	"context"
-	"errors"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"concurrent-pipeline-processor/internal/metrics"
 	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/internal/tracing"
 	"concurrent-pipeline-processor/pkg/models"
+	"concurrent-pipeline-processor/pkg/stage"
 
 	"golang.org/x/time/rate"
 )
 
 var (
-	ErrPipelineNotStarted = errors.New("pipeline not started")
-	ErrPipelineStopped    = errors.New("pipeline stopped")
-	ErrRateLimitExceeded  = errors.New("rate limit exceeded")
+	ErrPipelineNotStarted     = models.NewError(models.CodeNotStarted, "pipeline not started")
+	ErrPipelineAlreadyStarted = models.NewError(models.CodeAlreadyStarted, "pipeline already started")
+	ErrPipelineStopped        = models.NewError(models.CodeStopped, "pipeline stopped")
+	ErrRateLimitExceeded      = models.NewError(models.CodeRateLimited, "rate limit exceeded")
+	ErrIntakePaused           = models.NewError(models.CodeIntakePaused, "pipeline intake paused")
+	ErrPipelinePaused         = models.NewError(models.CodePaused, "pipeline paused")
 )
 
 type pipeline struct {
 	opts Options
 
-	input     chan models.Task
-	validated chan models.Task
-	processed chan models.Result
+	lanes     [models.PriorityTotalAmount]chan job
+	laneReady chan struct{}
+	input     chan job
+	validated <-chan job
+	work      chan job // fair queue output, nil without a fair queue
+	processed <-chan outcome
 	output    chan models.Result
 
-	started bool
-	stopped bool
-	mu      sync.RWMutex
-	wg      sync.WaitGroup
-	limiter *rate.Limiter
+	started      bool
+	stopped      bool
+	intakePaused bool
+	paused       bool
+	startedAt    time.Time
+	stoppedAt    time.Time
+	intakeClosed chan struct{}
+	done         chan struct{}
+	mu           sync.RWMutex
+	wg           sync.WaitGroup
+	limiter      *rate.Limiter
+	tenants      *tenantLimiters
+	pool         *stage.Pool[job, outcome]
+
+	// validate and process are the stage functions, replaceable in tests
+	validate func(models.Task) error
+	process  func(models.Task) models.Result
+
+	// validateChain and processChain wrap the stage functions in the
+	// configured interceptors
+	validateChain ValidateFunc
+	processChain  ProcessFunc
+
+	metrics    *pipelineMetrics
+	latency    latencyTracker
+	fairQueued atomic.Int64
+	throughput *throughputTracker
 }
 
 // NewPipeline creates a new pipeline with the given options
@@ -37,51 +69,167 @@ func NewPipeline(opts Options) (Pipeline, error) {
 	if err := opts.Validate(); err != nil {
 		return nil, err
 	}
+	opts = opts.withDefaults()
 
 	// Create rate limiter with burst size
-	limiter := rate.NewLimiter(rate.Limit(opts.TasksPerSecond), opts.BurstSize)
+	limiter := newLimiter(opts)
 
-	return &pipeline{
-		opts:      opts,
-		input:     make(chan models.Task, opts.InputBufferSize),
-		validated: make(chan models.Task, opts.InputBufferSize),
-		processed: make(chan models.Result, opts.ResultBufferSize),
-		output:    make(chan models.Result, opts.ResultBufferSize),
-		limiter:   limiter,
-	}, nil
+	var tenants *tenantLimiters
+	if opts.TenantRateLimit != nil {
+		tenants = newTenantLimiters(*opts.TenantRateLimit)
+	}
+
+	var lanes [models.PriorityTotalAmount]chan job
+	for i := range lanes {
+		lanes[i] = make(chan job, opts.InputBufferSize)
+	}
+
+	// Workers read validated tasks directly unless a fair queue sits in
+	// between; the other stage channels are created by Start
+	var work chan job
+	if opts.FairQueue != nil {
+		work = make(chan job)
+	}
+
+	// Overflow checks depend on the arithmetic the tasks are processed with
+	rules := opts.ValidationRules
+	rules.Mode = opts.Mode
+
+	process := opts.Mode.ProcessFunc(opts.Decimal)
+	if opts.ProgramCacheSize > 0 {
+		process = processor.NewProgramCache(opts.ProgramCacheSize).ProcessTask
+	}
+	if opts.ExplainErrors {
+		process = processor.ExplainErrors(process)
+	}
+
+	p := &pipeline{
+		opts:         opts,
+		lanes:        lanes,
+		laneReady:    make(chan struct{}, 1),
+		intakeClosed: make(chan struct{}),
+		done:         make(chan struct{}),
+		// Unbuffered, as is the validator's output, so that queued tasks
+		// wait in their lanes, where the scheduler can still reorder them by
+		// priority until a worker is free
+		input:      make(chan job),
+		work:       work,
+		output:     make(chan models.Result, opts.ResultBufferSize),
+		limiter:    limiter,
+		tenants:    tenants,
+		validate:   rules.ValidateTask,
+		process:    process,
+		throughput: newThroughputTracker(opts.ThroughputWindow),
+	}
+
+	reg := opts.Metrics
+	if reg == nil {
+		reg = metrics.NewRegistry()
+	}
+	p.metrics = newPipelineMetrics(reg, p)
+
+	p.validateChain = chainValidator(func(_ context.Context, task models.Task) (models.Task, error) {
+		return task, p.validate(task)
+	}, opts.Interceptors.Validator)
+	p.processChain = chainProcessor(func(_ context.Context, task models.Task) models.Result {
+		return p.process(task)
+	}, opts.Interceptors.Processor)
+
+	var poolOpts []stage.Option
+	if opts.ErrorPolicy == ErrorPolicyRestart {
+		poolOpts = append(poolOpts, stage.RestartOnError())
+	}
+	poolOpts = append(poolOpts, stage.WithBuffer(opts.ResultBufferSize))
+	p.pool = stage.NewPool(opts.NumWorkers, p.processJob, p.failJob, poolOpts...)
+
+	return p, nil
 }
 
 func (p *pipeline) Start(ctx context.Context) error {
 	p.mu.Lock()
 	if p.started {
 		p.mu.Unlock()
-		return errors.New("pipeline already started")
+		return ErrPipelineAlreadyStarted
 	}
 	p.started = true
-	p.mu.Unlock()
+	p.startedAt = time.Now()
 
 	// Start the pipeline stages
-	p.wg.Add(3) // validator, processor, aggregator
+	p.wg.Add(3) // lane scheduler, validator, aggregator
 
 	// Start validator
-	go p.runValidator(ctx)
+	validate := stage.TryMap(p.validateJob, p.rejectJob, stage.OnClose(p.wg.Done))
+	p.validated = validate(ctx, p.input)
+	work := p.validated
+	if p.work != nil {
+		work = p.work
+	}
 
-	// Start processor workers
-	go p.runProcessor(ctx)
+	// Start processor workers, holding the lock so a concurrent SetWorkers
+	// either updates the count before the pool starts or resizes it afterwards
+	p.processed = p.pool.Run(ctx, work)
+	p.mu.Unlock()
+
+	// Start lane scheduler
+	go p.runLaneScheduler(ctx)
+
+	// Start fair queue between validator and workers
+	if p.opts.FairQueue != nil {
+		p.wg.Add(1)
+		go p.runFairQueue(ctx, *p.opts.FairQueue)
+	}
 
 	// Start aggregator
 	go p.runAggregator(ctx)
 
+	// Start autoscaler
+	if p.opts.Autoscale != nil {
+		go p.runAutoscaler(ctx, *p.opts.Autoscale)
+	}
+
+	// Close the output once every stage has exited
+	go func() {
+		p.wg.Wait()
+		p.closeIntake()
+		close(p.output)
+		close(p.done)
+	}()
+
 	// Monitor context cancellation
 	go func() {
-		<-ctx.Done()
-		p.shutdown()
+		select {
+		case <-ctx.Done():
+			p.closeIntake()
+		case <-p.done:
+		}
 	}()
 
 	return nil
 }
 
 func (p *pipeline) AddTask(task models.Task) error {
+	return p.AddTaskContext(context.Background(), task)
+}
+
+func (p *pipeline) AddTaskContext(ctx context.Context, task models.Task) error {
+	_, span := p.opts.Tracer.Start(ctx, spanAdmit)
+	span.SetAttributes(
+		tracing.String("task.tenant", task.Tenant),
+		tracing.String("task.priority", task.Priority.String()),
+	)
+
+	err := models.WrapError(p.admit(job{task: task, trace: span.SpanContext()}), models.StageIntake, task.ID, -1)
+	p.metrics.recordAdmission(err)
+
+	span.RecordError(err)
+	span.End()
+	return err
+}
+
+// admit applies the admission checks and rate limits and queues the task
+func (p *pipeline) admit(j job) error {
+	task := j.task
+
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -91,99 +239,160 @@ func (p *pipeline) AddTask(task models.Task) error {
 	if p.stopped {
 		return ErrPipelineStopped
 	}
+	if p.intakePaused {
+		return ErrIntakePaused
+	}
+	if p.paused && p.opts.PausePolicy == PausePolicyReject {
+		return ErrPipelinePaused
+	}
+	if task.Priority < 0 || task.Priority >= models.PriorityTotalAmount {
+		return ErrInvalidPriority
+	}
+
+	// Try to acquire the tenant's token first so a noisy tenant is
+	// rejected without consuming global capacity
+	// Tokens are only handed back when cancelled at the reservation time
+	now := time.Now()
+	var tenantToken *rate.Reservation
+	if p.tenants != nil {
+		if tenantToken = p.tenants.reserve(task.Tenant, now); tenantToken == nil {
+			return ErrTenantRateLimitExceeded
+		}
+	}
 
 	// Try to acquire rate limit token
 	if !p.limiter.Allow() {
+		if tenantToken != nil {
+			tenantToken.CancelAt(now)
+		}
 		return ErrRateLimitExceeded
 	}
 
-	select {
-	case p.input <- task:
-		return nil
-	default:
-		return errors.New("pipeline buffer full")
+	if err := p.enqueue(j); err != nil {
+		// The task was not admitted, so hand the tenant's token back
+		if tenantToken != nil {
+			tenantToken.CancelAt(now)
+		}
+		return err
 	}
+	return nil
 }
 
 func (p *pipeline) Results() <-chan models.Result {
 	return p.output
 }
 
-func (p *pipeline) shutdown() {
+func (p *pipeline) SetWorkers(n int) error {
+	if n <= 0 {
+		return ErrInvalidNumWorkers
+	}
+
 	p.mu.Lock()
-	if !p.stopped {
-		p.stopped = true
-		close(p.input)
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return ErrPipelineStopped
 	}
-	p.mu.Unlock()
+	p.opts.NumWorkers = n
+	p.pool.Resize(n)
+	return nil
+}
 
-	p.wg.Wait()
-	close(p.output)
+func (p *pipeline) Workers() int {
+	return p.pool.Size()
 }
 
-// Stage implementations will be added in separate files
-func (p *pipeline) runValidator(ctx context.Context) {
-	defer func() {
-		close(p.validated)
-		p.wg.Done()
-	}()
+func (p *pipeline) PauseIntake() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case task, ok := <-p.input:
-			if !ok {
-				return
-			}
-			if err := processor.ValidateTask(task); err != nil {
-				p.output <- models.Result{Error: err}
-				continue
-			}
-			select {
-			case p.validated <- task:
-			case <-ctx.Done():
-				return
-			}
-		}
+	p.intakePaused = true
+}
+
+func (p *pipeline) ResumeIntake() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.intakePaused = false
+}
+
+func (p *pipeline) IntakePaused() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.intakePaused
+}
+
+func (p *pipeline) Drain(ctx context.Context) error {
+	p.mu.RLock()
+	started := p.started
+	p.mu.RUnlock()
+	if !started {
+		return ErrPipelineNotStarted
+	}
+
+	p.closeIntake()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-func (p *pipeline) runProcessor(ctx context.Context) {
-	defer p.wg.Done()
+func (p *pipeline) Ready() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.started && !p.stopped
+}
 
-	// Create worker pool
-	var wg sync.WaitGroup
-	wg.Add(p.opts.NumWorkers)
+// closeIntake stops accepting tasks. The stages finish the queued tasks and
+// exit, unless the context is cancelled first.
+func (p *pipeline) closeIntake() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	// Start workers
-	for i := 0; i < p.opts.NumWorkers; i++ {
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case task, ok := <-p.validated:
-					if !ok {
-						return
-					}
-					result := processor.ProcessTask(task)
-					select {
-					case p.processed <- result:
-					case <-ctx.Done():
-						return
-					}
-				}
-			}
-		}()
+	if p.stopped {
+		return
 	}
+	p.stopped = true
+	p.stoppedAt = time.Now()
+	close(p.intakeClosed)
 
-	// Wait for all workers to finish and close processed channel
-	go func() {
-		wg.Wait()
-		close(p.processed)
-	}()
+	// A paused pipeline could never finish its queued tasks
+	p.resumeLocked()
+}
+
+// validateJob is the validator stage function
+func (p *pipeline) validateJob(ctx context.Context, j job) (job, error) {
+	span := p.startSpan(j.trace, spanValidate)
+	ctx = tracing.ContextWithSpanContext(ctx, span.SpanContext())
+	var err error
+	start := time.Now()
+	if perr := p.guard(stageValidator, &j.task, func() { j.task, err = p.validateChain(ctx, j.task) }); perr != nil {
+		err = perr
+	}
+	p.metrics.observeStage(stageValidator, start)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		p.metrics.failed.With(stageValidator).Inc()
+		return j, err
+	}
+	p.metrics.validated.Inc()
+	return j, nil
+}
+
+// rejectJob sends the error of a task that failed validation straight to the
+// output, skipping the remaining stages
+func (p *pipeline) rejectJob(ctx context.Context, _ job, err error) (job, bool) {
+	select {
+	case p.output <- models.Result{Error: err}:
+	case <-ctx.Done():
+	}
+	return job{}, false
 }
 
 func (p *pipeline) runAggregator(ctx context.Context) {
@@ -193,24 +402,87 @@ func (p *pipeline) runAggregator(ctx context.Context) {
 	defer agg.Close()
 
 	resultChan := agg.Results()
+	window := newWindowTrace(p.opts.Tracer)
+	// start is the aggregation start of the current task; the window link is
+	// recorded after the interceptors so dropped results are not linked
+	var start time.Time
+	aggregate := chainAggregator(func(ctx context.Context, result models.Result) {
+		agg.Add(result)
+		if result.Error == nil {
+			window.add(tracing.SpanContextFromContext(ctx), start)
+		}
+	}, p.opts.Interceptors.Aggregator)
 
 	for {
 		select {
 		case <-ctx.Done():
 			agg.Flush()
 			return
-		case result, ok := <-p.processed:
+		case out, ok := <-p.processed:
 			if !ok {
 				agg.Flush()
+				p.forwardPending(ctx, resultChan, window)
+				return
+			}
+			// Forward the emitted results first so Add never blocks on a
+			// full aggregator channel this loop is responsible for draining
+			if !p.forwardPending(ctx, resultChan, window) {
 				return
 			}
-			agg.Add(result)
+			span := p.startSpan(out.trace, spanAggregate)
+			sctx := tracing.ContextWithSpanContext(ctx, span.SpanContext())
+			start = time.Now()
+			perr := p.guard(stageAggregator, nil, func() { aggregate(sctx, out.result) })
+			p.metrics.observeStage(stageAggregator, start)
+			span.RecordError(perr)
+			span.End()
+			if perr != nil {
+				p.metrics.failed.With(stageAggregator).Inc()
+				select {
+				case p.output <- models.Result{Error: perr}:
+				case <-ctx.Done():
+					return
+				}
+			}
 		case result := <-resultChan:
-			select {
-			case p.output <- result:
-			case <-ctx.Done():
+			if !p.forward(ctx, result, window) {
 				return
 			}
 		}
 	}
 }
+
+// forward records an aggregator result and sends it to the output, returning
+// false if the context is cancelled first
+func (p *pipeline) forward(ctx context.Context, result models.Result, window *windowTrace) bool {
+	if result.Error == nil {
+		p.metrics.aggregated.Inc()
+	}
+	// Failed tasks pass through the aggregator without joining a window,
+	// while a window whose sum fails is reported by the aggregator stage
+	if result.Error == nil || models.StageOf(result.Error) == models.StageAggregator {
+		window.emit(p.opts.AggregationWindow, result)
+	}
+	select {
+	case p.output <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// forwardPending forwards the results the aggregator has already emitted,
+// including a final flushed window, returning false if the context is
+// cancelled first
+func (p *pipeline) forwardPending(ctx context.Context, resultChan <-chan models.Result, window *windowTrace) bool {
+	for {
+		select {
+		case result := <-resultChan:
+			if !p.forward(ctx, result, window) {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
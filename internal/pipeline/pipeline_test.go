@@ -2,9 +2,12 @@ package pipeline
 
 import (
 	"context"
+	"errors"
+	"math"
 	"testing"
 	"time"
 
+	"concurrent-pipeline-processor/internal/processor"
 	"concurrent-pipeline-processor/pkg/models"
 )
 
@@ -65,6 +68,223 @@ func TestPipeline(t *testing.T) {
 		}
 	})
 
+	t.Run("aggregates exactly in arbitrary precision mode", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        2,
+			AggregationWindow: 2,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   100,
+			ResultBufferSize:  100,
+			Mode:              processor.ModeBig,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		// Each task overflows int, so the validator would reject it in ModeInt
+		task := models.Task{
+			Value:      math.MaxInt,
+			Operations: []models.Operation{{Operator: models.OperatorMultiply, Value: 4}},
+		}
+		for i := 0; i < 2; i++ {
+			if err := p.AddTask(task); err != nil {
+				t.Fatalf("Failed to add task: %v", err)
+			}
+		}
+
+		select {
+		case result := <-p.Results():
+			if result.Error != nil {
+				t.Fatalf("Unexpected error: %v", result.Error)
+			}
+			if got := result.Exact(); got != "73786976294838206456" { // 2 * 4 * MaxInt
+				t.Errorf("Expected sum 73786976294838206456, got %s", got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("Timeout waiting for results")
+		}
+	})
+
+	t.Run("explains processor errors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        1,
+			AggregationWindow: 1,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   100,
+			ResultBufferSize:  100,
+			ExplainErrors:     true,
+			Interceptors: Interceptors{
+				// Operations added after validation are only checked when
+				// they are applied, as a fee looked up per task would be
+				Validator: []ValidatorInterceptor{
+					func(ctx context.Context, task models.Task, next ValidateFunc) (models.Task, error) {
+						task, err := next(ctx, task)
+						task.Operations = append(task.Operations, models.Operation{Operator: models.OperatorDivide, Value: 0})
+						return task, err
+					},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		task := models.Task{
+			Value: 10,
+			Operations: []models.Operation{
+				{Operator: models.OperatorMultiply, Value: 2},
+			},
+		}
+		if err := p.AddTask(task); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+
+		select {
+		case result := <-p.Results():
+			if !errors.Is(result.Error, processor.ErrDivisionByZero) {
+				t.Fatalf("Expected ErrDivisionByZero, got %v", result.Error)
+			}
+			if result.Explanation == nil || len(result.Explanation.Steps) != 1 {
+				t.Fatalf("Expected an explanation stopping after one step, got %+v", result.Explanation)
+			}
+			if got := result.Explanation.Steps[0].Result; got != 20 {
+				t.Errorf("Expected intermediate value 20, got %d", got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("Timeout waiting for results")
+		}
+	})
+
+	t.Run("rejects explaining errors outside int mode", func(t *testing.T) {
+		_, err := NewPipeline(Options{
+			NumWorkers:        1,
+			AggregationWindow: 1,
+			TasksPerSecond:    100,
+			Mode:              processor.ModeBig,
+			ExplainErrors:     true,
+		})
+		if !errors.Is(err, ErrExplainErrorsMode) {
+			t.Errorf("Expected ErrExplainErrorsMode, got %v", err)
+		}
+	})
+
+	t.Run("applies default buffer sizes", func(t *testing.T) {
+		for _, fq := range []*FairQueueOptions{nil, {}} {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Burst and buffer sizes are left to their defaults
+			p, err := NewPipeline(Options{
+				NumWorkers:        1,
+				AggregationWindow: 2,
+				TasksPerSecond:    100,
+				FairQueue:         fq,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create pipeline: %v", err)
+			}
+			if err := p.Start(ctx); err != nil {
+				t.Fatalf("Failed to start pipeline: %v", err)
+			}
+
+			accepted := 0
+			for i := 0; i < 20; i++ {
+				if p.AddTask(models.Task{Value: i, Operations: []models.Operation{}}) == nil {
+					accepted++
+				}
+			}
+			// Each lane buffers NumWorkers * AggregationWindow * 2 tasks
+			if accepted < 4 {
+				t.Errorf("Expected at least 4 accepted tasks with fair queue %v, got %d", fq != nil, accepted)
+			}
+		}
+	})
+
+	t.Run("processes tasks with cached programs", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        2,
+			AggregationWindow: 3,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   100,
+			ResultBufferSize:  100,
+			ProgramCacheSize:  4,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		ops := []models.Operation{
+			{Operator: models.OperatorPlus, Value: 5},
+			{Operator: models.OperatorMultiply, Value: 3},
+			{Operator: models.OperatorDivide, Value: -2},
+		}
+		for _, v := range []int{1, 2, 3} {
+			if err := p.AddTask(models.Task{Value: v, Operations: ops}); err != nil {
+				t.Fatalf("Failed to add task: %v", err)
+			}
+		}
+
+		select {
+		case result := <-p.Results():
+			if result.Error != nil {
+				t.Fatalf("Unexpected error: %v", result.Error)
+			}
+			if result.Result != -31 { // -9 + -10 + -12
+				t.Errorf("Expected sum -31, got %d", result.Result)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("Timeout waiting for results")
+		}
+	})
+
+	t.Run("rejects invalid program caches", func(t *testing.T) {
+		tests := []struct {
+			name string
+			mode processor.Mode
+			size int
+			want error
+		}{
+			{name: "negative size", mode: processor.ModeInt, size: -1, want: ErrInvalidProgramCacheSize},
+			{name: "outside int mode", mode: processor.ModeFloat, size: 4, want: ErrProgramCacheMode},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := NewPipeline(Options{
+					NumWorkers:        1,
+					AggregationWindow: 1,
+					TasksPerSecond:    100,
+					Mode:              tt.mode,
+					ProgramCacheSize:  tt.size,
+				})
+				if !errors.Is(err, tt.want) {
+					t.Errorf("Expected %v, got %v", tt.want, err)
+				}
+			})
+		}
+	})
+
 	t.Run("handles validation errors", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -143,7 +363,7 @@ func TestPipeline(t *testing.T) {
 
 		// Try to add task after shutdown
 		err = p.AddTask(task)
-		if err != ErrPipelineStopped {
+		if !errors.Is(err, ErrPipelineStopped) {
 			t.Errorf("Expected ErrPipelineStopped, got %v", err)
 		}
 	})
@@ -181,8 +401,112 @@ func TestPipeline(t *testing.T) {
 		}
 
 		// Second task should be rate limited
-		if err := p.AddTask(task); err != ErrRateLimitExceeded {
+		task.ID = "limited"
+		err = p.AddTask(task)
+		if !errors.Is(err, ErrRateLimitExceeded) {
 			t.Errorf("Expected ErrRateLimitExceeded, got %v", err)
 		}
+		var perr *models.Error
+		if !errors.As(err, &perr) {
+			t.Fatalf("Expected *models.Error, got %T", err)
+		}
+		if perr.Code != models.CodeRateLimited || perr.Stage != models.StageIntake || perr.TaskID != "limited" {
+			t.Errorf("Expected rate_limited/intake/limited, got %s/%s/%s", perr.Code, perr.Stage, perr.TaskID)
+		}
+	})
+	t.Run("pauses and resumes intake", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        1,
+			AggregationWindow: 1,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   100,
+			ResultBufferSize:  100,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		task := models.Task{Value: 1, Operations: []models.Operation{}}
+
+		p.PauseIntake()
+		if !p.IntakePaused() {
+			t.Error("Expected intake to be paused")
+		}
+		if err := p.AddTask(task); !errors.Is(err, ErrIntakePaused) {
+			t.Errorf("Expected ErrIntakePaused, got %v", err)
+		}
+		if !p.Ready() {
+			t.Error("Expected pipeline with paused intake to stay ready")
+		}
+
+		p.ResumeIntake()
+		if err := p.AddTask(task); err != nil {
+			t.Errorf("Failed to add task after resume: %v", err)
+		}
+	})
+
+	t.Run("drains queued tasks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        2,
+			AggregationWindow: 2,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   100,
+			ResultBufferSize:  100,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+
+		if err := p.Drain(ctx); err != ErrPipelineNotStarted {
+			t.Errorf("Expected ErrPipelineNotStarted, got %v", err)
+		}
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+		if !p.Ready() {
+			t.Error("Expected started pipeline to be ready")
+		}
+
+		// Three tasks leave a partial window that must be flushed on drain
+		for i := 1; i <= 3; i++ {
+			if err := p.AddTask(models.Task{Value: i, Operations: []models.Operation{}}); err != nil {
+				t.Fatalf("Failed to add task: %v", err)
+			}
+		}
+
+		drainCtx, drainCancel := context.WithTimeout(ctx, 2*time.Second)
+		defer drainCancel()
+		if err := p.Drain(drainCtx); err != nil {
+			t.Fatalf("Drain() error = %v", err)
+		}
+		if p.Ready() {
+			t.Error("Expected drained pipeline not to be ready")
+		}
+		if err := p.AddTask(models.Task{Value: 1}); !errors.Is(err, ErrPipelineStopped) {
+			t.Errorf("Expected ErrPipelineStopped, got %v", err)
+		}
+
+		sum := 0
+		for result := range p.Results() {
+			if result.Error != nil {
+				t.Errorf("Unexpected error: %v", result.Error)
+			}
+			sum += result.Result
+		}
+		if sum != 6 {
+			t.Errorf("Expected drained results to sum to 6, got %d", sum)
+		}
 	})
 }
@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"golang.org/x/time/rate"
+)
+
+// newLimiter creates the admission rate limiter described by the options
+func newLimiter(opts Options) *rate.Limiter {
+	if opts.DisableRateLimit {
+		return rate.NewLimiter(rate.Inf, opts.BurstSize)
+	}
+	return rate.NewLimiter(rate.Limit(opts.TasksPerSecond), opts.BurstSize)
+}
+
+func (p *pipeline) SetRateLimit(tasksPerSecond, burst int) error {
+	if tasksPerSecond <= 0 {
+		return ErrInvalidRateLimit
+	}
+	if burst < tasksPerSecond {
+		burst = tasksPerSecond
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.opts.TasksPerSecond = tasksPerSecond
+	p.opts.BurstSize = burst
+	p.opts.DisableRateLimit = false
+
+	// Burst first so the new rate never applies to a stale bucket size
+	p.limiter.SetBurst(burst)
+	p.limiter.SetLimit(rate.Limit(tasksPerSecond))
+	return nil
+}
+
+func (p *pipeline) DisableRateLimit() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.opts.DisableRateLimit = true
+	p.limiter.SetLimit(rate.Inf)
+}
+
+func (p *pipeline) RateLimit() (tasksPerSecond, burst int, enabled bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.opts.TasksPerSecond, p.opts.BurstSize, !p.opts.DisableRateLimit
+}
@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestRuntimeRateLimit(t *testing.T) {
+	task := models.Task{
+		Value: 2,
+		Operations: []models.Operation{
+			{Operator: models.OperatorPlus, Value: 3},
+		},
+	}
+
+	newStartedPipeline := func(t *testing.T, ctx context.Context, opts Options) Pipeline {
+		p, err := NewPipeline(opts)
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+		return p
+	}
+
+	baseOpts := Options{
+		NumWorkers:        2,
+		AggregationWindow: 100,
+		TasksPerSecond:    1,
+		BurstSize:         1,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+	}
+
+	t.Run("rejects invalid rate", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := newStartedPipeline(t, ctx, baseOpts)
+		if err := p.SetRateLimit(0, 10); err != ErrInvalidRateLimit {
+			t.Errorf("Expected ErrInvalidRateLimit, got %v", err)
+		}
+	})
+
+	t.Run("raises limit on running pipeline", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := newStartedPipeline(t, ctx, baseOpts)
+		if err := p.AddTask(task); err != nil {
+			t.Fatalf("Failed to add first task: %v", err)
+		}
+		if err := p.AddTask(task); !errors.Is(err, ErrRateLimitExceeded) {
+			t.Fatalf("Expected ErrRateLimitExceeded, got %v", err)
+		}
+
+		if err := p.SetRateLimit(1000, 10); err != nil {
+			t.Fatalf("SetRateLimit() error = %v", err)
+		}
+		tps, burst, enabled := p.RateLimit()
+		if tps != 1000 || burst != 1000 || !enabled {
+			t.Errorf("Expected rate 1000/1000 enabled, got %d/%d enabled=%v", tps, burst, enabled)
+		}
+
+		// The refill at the new rate quickly admits more tasks
+		admitted := 0
+		deadline := time.Now().Add(time.Second)
+		for admitted < 5 && time.Now().Before(deadline) {
+			if err := p.AddTask(task); err == nil {
+				admitted++
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+		if admitted < 5 {
+			t.Errorf("Expected tasks to be admitted after raising the limit, got %d", admitted)
+		}
+	})
+
+	t.Run("disables and re-enables limiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := newStartedPipeline(t, ctx, baseOpts)
+		p.DisableRateLimit()
+		if _, _, enabled := p.RateLimit(); enabled {
+			t.Error("Expected rate limiting to be disabled")
+		}
+
+		for i := 0; i < 50; i++ {
+			if err := p.AddTask(task); err != nil {
+				t.Fatalf("Expected unlimited admission, task %d got %v", i, err)
+			}
+		}
+
+		if err := p.SetRateLimit(1, 1); err != nil {
+			t.Fatalf("SetRateLimit() error = %v", err)
+		}
+		limited := false
+		for i := 0; i < 3; i++ {
+			if err := p.AddTask(task); errors.Is(err, ErrRateLimitExceeded) {
+				limited = true
+			}
+		}
+		if !limited {
+			t.Error("Expected rate limiting to apply after re-enabling")
+		}
+	})
+
+	t.Run("starts disabled from options", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		opts := baseOpts
+		opts.DisableRateLimit = true
+		p := newStartedPipeline(t, ctx, opts)
+		for i := 0; i < 10; i++ {
+			if err := p.AddTask(task); err != nil {
+				t.Fatalf("Expected unlimited admission, task %d got %v", i, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"concurrent-pipeline-processor/internal/logger"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+const (
+	stageValidator  = models.StageValidator
+	stageProcessor  = models.StageProcessor
+	stageAggregator = models.StageAggregator
+)
+
+// stages lists the stages in pipeline order
+var stages = []string{stageValidator, stageProcessor, stageAggregator}
+
+// ErrStagePanic matches every PanicError via errors.Is
+var ErrStagePanic = models.NewError(models.CodePanic, "stage panicked")
+
+// PanicError is returned in a result when a stage recovers from a panic
+type PanicError struct {
+	// Stage is the name of the stage that panicked
+	Stage string
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack is the stack trace captured at the point of recovery
+	Stack []byte
+	// Task is the task being handled when the panic occurred, if any
+	Task *models.Task
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in %s stage: %v", e.Stage, e.Value)
+}
+
+// Unwrap returns ErrStagePanic wrapped with the stage and the ID of the task,
+// so errors.As to a *models.Error tells where the panic happened
+func (e *PanicError) Unwrap() error {
+	var taskID string
+	if e.Task != nil {
+		taskID = e.Task.ID
+	}
+	return models.WrapError(ErrStagePanic, e.Stage, taskID, -1)
+}
+
+// guard runs fn and converts a panic inside it into a PanicError
+func (p *pipeline) guard(stage string, task *models.Task, fn func()) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		p.metrics.panics.With(stage).Inc()
+		perr := &PanicError{
+			Stage: stage,
+			Value: r,
+			Stack: debug.Stack(),
+		}
+		if task != nil {
+			t := *task
+			perr.Task = &t
+		}
+
+		logger.Error().
+			Str("stage", stage).
+			Interface("panic", r).
+			Bytes("stack", perr.Stack).
+			Msg("Recovered from panic in pipeline stage")
+
+		err = perr
+	}()
+
+	fn()
+	return nil
+}
@@ -0,0 +1,153 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	policies := []struct {
+		name   string
+		policy ErrorPolicy
+	}{
+		{name: "continue policy", policy: ErrorPolicyContinue},
+		{name: "restart policy", policy: ErrorPolicyRestart},
+	}
+
+	for _, tt := range policies {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			p, err := NewPipeline(Options{
+				NumWorkers:        1,
+				AggregationWindow: 1,
+				TasksPerSecond:    100,
+				BurstSize:         200,
+				InputBufferSize:   100,
+				ResultBufferSize:  100,
+				ErrorPolicy:       tt.policy,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create pipeline: %v", err)
+			}
+
+			// Panic on tasks with a negative value
+			impl := p.(*pipeline)
+			impl.process = func(task models.Task) models.Result {
+				if task.Value < 0 {
+					panic("boom")
+				}
+				return processor.ProcessTask(task)
+			}
+
+			if err := p.Start(ctx); err != nil {
+				t.Fatalf("Failed to start pipeline: %v", err)
+			}
+
+			bad := models.Task{ID: "bad", Value: -1, Operations: []models.Operation{}}
+			good := models.Task{Value: 7, Operations: []models.Operation{}}
+
+			if err := p.AddTask(bad); err != nil {
+				t.Fatalf("Failed to add task: %v", err)
+			}
+
+			select {
+			case result := <-p.Results():
+				var perr *PanicError
+				if !errors.As(result.Error, &perr) {
+					t.Fatalf("Expected PanicError, got %v", result.Error)
+				}
+				if !errors.Is(result.Error, ErrStagePanic) {
+					t.Error("Expected error to match ErrStagePanic")
+				}
+				if perr.Stage != stageProcessor {
+					t.Errorf("Expected stage %s, got %s", stageProcessor, perr.Stage)
+				}
+				if perr.Task == nil || perr.Task.Value != -1 {
+					t.Errorf("Expected panicking task to be attached, got %+v", perr.Task)
+				}
+				if len(perr.Stack) == 0 {
+					t.Error("Expected stack trace to be captured")
+				}
+				var merr *models.Error
+				if !errors.As(result.Error, &merr) || merr.Stage != models.StageProcessor || merr.TaskID != "bad" || merr.Index != -1 {
+					t.Errorf("Expected a processor error for task bad, got %+v", merr)
+				}
+				if code := models.CodeOf(result.Error); code != models.CodePanic {
+					t.Errorf("Expected code %s, got %s", models.CodePanic, code)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timeout waiting for panic result")
+			}
+
+			// The pool must still process tasks after the panic
+			if err := p.AddTask(good); err != nil {
+				t.Fatalf("Failed to add task: %v", err)
+			}
+
+			select {
+			case result := <-p.Results():
+				if result.Error != nil {
+					t.Errorf("Unexpected error: %v", result.Error)
+				}
+				if result.Result != 7 {
+					t.Errorf("Expected result 7, got %d", result.Result)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timeout waiting for result after panic")
+			}
+
+			if got := impl.metrics.panics.With(stageProcessor).Value(); got != 1 {
+				t.Errorf("Expected 1 recorded panic, got %d", got)
+			}
+		})
+	}
+
+	t.Run("validator panic", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        1,
+			AggregationWindow: 1,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   100,
+			ResultBufferSize:  100,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+
+		p.(*pipeline).validate = func(models.Task) error {
+			panic("validator boom")
+		}
+
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		if err := p.AddTask(models.Task{Value: 1, Operations: []models.Operation{}}); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+
+		select {
+		case result := <-p.Results():
+			var perr *PanicError
+			if !errors.As(result.Error, &perr) {
+				t.Fatalf("Expected PanicError, got %v", result.Error)
+			}
+			if perr.Stage != stageValidator {
+				t.Errorf("Expected stage %s, got %s", stageValidator, perr.Stage)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for panic result")
+		}
+	})
+}
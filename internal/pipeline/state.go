@@ -0,0 +1,83 @@
+package pipeline
+
+// State describes the lifecycle stage of a pipeline
+type State int
+
+const (
+	// StateCreated means the pipeline has not been started
+	StateCreated State = iota
+	// StateRunning means the pipeline accepts and processes tasks
+	StateRunning
+	// StatePaused means workers have stopped taking tasks until Resume is called
+	StatePaused
+	// StateDraining means intake is closed and queued tasks are being finished
+	StateDraining
+	// StateStopped means every stage has exited and the results channel is closed
+	StateStopped
+)
+
+// String returns the lower-case name of the state
+func (s State) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateRunning:
+		return "running"
+	case StatePaused:
+		return "paused"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+func (p *pipeline) Pause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.started {
+		return ErrPipelineNotStarted
+	}
+	if p.stopped {
+		return ErrPipelineStopped
+	}
+	p.paused = true
+	p.pool.Pause()
+	return nil
+}
+
+func (p *pipeline) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.resumeLocked()
+}
+
+func (p *pipeline) resumeLocked() {
+	p.paused = false
+	p.pool.Resume()
+}
+
+func (p *pipeline) State() State {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	switch {
+	case !p.started:
+		return StateCreated
+	case p.stopped:
+		select {
+		case <-p.done:
+			return StateStopped
+		default:
+			return StateDraining
+		}
+	case p.paused:
+		return StatePaused
+	default:
+		return StateRunning
+	}
+}
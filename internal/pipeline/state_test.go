@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestPauseResume(t *testing.T) {
+	newPipeline := func(t *testing.T, policy PausePolicy) *pipeline {
+		t.Helper()
+
+		p, err := NewPipeline(Options{
+			NumWorkers:        2,
+			AggregationWindow: 1,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   100,
+			ResultBufferSize:  100,
+			PausePolicy:       policy,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+		return p.(*pipeline)
+	}
+	task := models.Task{Value: 1, Operations: []models.Operation{}}
+
+	t.Run("buffers tasks while paused", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := newPipeline(t, PausePolicyBuffer)
+		if got := p.State(); got != StateCreated {
+			t.Errorf("Expected state created, got %s", got)
+		}
+		if err := p.Pause(); err != ErrPipelineNotStarted {
+			t.Errorf("Expected ErrPipelineNotStarted, got %v", err)
+		}
+
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+		if got := p.State(); got != StateRunning {
+			t.Errorf("Expected state running, got %s", got)
+		}
+
+		if err := p.Pause(); err != nil {
+			t.Fatalf("Pause() error = %v", err)
+		}
+		if got := p.State(); got != StatePaused {
+			t.Errorf("Expected state paused, got %s", got)
+		}
+
+		for i := 0; i < 3; i++ {
+			if err := p.AddTask(task); err != nil {
+				t.Fatalf("Failed to add task while paused: %v", err)
+			}
+		}
+
+		select {
+		case result := <-p.Results():
+			t.Fatalf("Expected no results while paused, got %+v", result)
+		case <-time.After(100 * time.Millisecond):
+		}
+		if got := p.metrics.processed.Value(); got != 0 {
+			t.Errorf("Expected no tasks processed while paused, got %d", got)
+		}
+
+		p.Resume()
+		if got := p.State(); got != StateRunning {
+			t.Errorf("Expected state running after resume, got %s", got)
+		}
+		for i := 0; i < 3; i++ {
+			select {
+			case <-p.Results():
+			case <-time.After(time.Second):
+				t.Fatal("Timeout waiting for results after resume")
+			}
+		}
+	})
+
+	t.Run("rejects tasks while paused", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := newPipeline(t, PausePolicyReject)
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		if err := p.Pause(); err != nil {
+			t.Fatalf("Pause() error = %v", err)
+		}
+		if err := p.AddTask(task); !errors.Is(err, ErrPipelinePaused) {
+			t.Errorf("Expected ErrPipelinePaused, got %v", err)
+		}
+
+		p.Resume()
+		if err := p.AddTask(task); err != nil {
+			t.Errorf("Failed to add task after resume: %v", err)
+		}
+	})
+
+	t.Run("drain resumes a paused pipeline", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := newPipeline(t, PausePolicyBuffer)
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+		if err := p.Pause(); err != nil {
+			t.Fatalf("Pause() error = %v", err)
+		}
+		if err := p.AddTask(task); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+
+		drainCtx, drainCancel := context.WithTimeout(ctx, 2*time.Second)
+		defer drainCancel()
+		if err := p.Drain(drainCtx); err != nil {
+			t.Fatalf("Drain() error = %v", err)
+		}
+		if got := p.State(); got != StateStopped {
+			t.Errorf("Expected state stopped, got %s", got)
+		}
+		if err := p.Pause(); err != ErrPipelineStopped {
+			t.Errorf("Expected ErrPipelineStopped, got %v", err)
+		}
+
+		sum := 0
+		for result := range p.Results() {
+			sum += result.Result
+		}
+		if sum != 1 {
+			t.Errorf("Expected drained result 1, got %d", sum)
+		}
+	})
+}
+
+func TestStateString(t *testing.T) {
+	tests := []struct {
+		state State
+		want  string
+	}{
+		{StateCreated, "created"},
+		{StateRunning, "running"},
+		{StatePaused, "paused"},
+		{StateDraining, "draining"},
+		{StateStopped, "stopped"},
+		{State(42), "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.state.String(); got != tt.want {
+				t.Errorf("State(%d).String() = %s, want %s", tt.state, got, tt.want)
+			}
+		})
+	}
+}
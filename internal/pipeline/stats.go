@@ -0,0 +1,179 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"concurrent-pipeline-processor/internal/metrics"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// defaultThroughputWindow is the period Stats reports throughput over when
+// Options.ThroughputWindow is zero
+const defaultThroughputWindow = 10 * time.Second
+
+// Stats is a point-in-time snapshot of the pipeline. Counters are cumulative
+// since the pipeline was created.
+type Stats struct {
+	// Uptime is the time since Start, frozen once the pipeline stops
+	Uptime time.Duration
+	// Accepted is the number of tasks admitted by AddTask
+	Accepted uint64
+	// Rejected is the number of tasks rejected by AddTask, by reason
+	Rejected map[string]uint64
+	// Validated is the number of tasks that passed validation
+	Validated uint64
+	// Processed is the number of tasks processed without error
+	Processed uint64
+	// Failed is the number of tasks that produced an error result, by stage
+	Failed map[string]uint64
+	// Aggregated is the number of aggregation windows emitted
+	Aggregated uint64
+	// Panics is the number of panics recovered across all stages
+	Panics uint64
+	// Queues holds the current queue lengths
+	Queues QueueStats
+	// Workers is the number of running processor workers
+	Workers int
+	// RateLimitEnabled reports whether admission is rate limited
+	RateLimitEnabled bool
+	// RateLimitTokens is the number of admission tokens currently available;
+	// zero when rate limiting is disabled
+	RateLimitTokens float64
+	// Throughput is the rate of successfully processed tasks per second
+	// over the last ThroughputWindow
+	Throughput float64
+	// ThroughputWindow is the period Throughput was measured over
+	ThroughputWindow time.Duration
+}
+
+// QueueStats holds the number of items waiting between pipeline stages
+type QueueStats struct {
+	// Lanes is the number of tasks queued in each priority lane
+	Lanes map[models.Priority]int
+	// Validated is the number of validated tasks waiting for a worker,
+	// including tasks held by the fair queue
+	Validated int
+	// Processed is the number of results waiting for the aggregator
+	Processed int
+	// Output is the number of results waiting to be read from Results
+	Output int
+}
+
+func (p *pipeline) Stats() Stats {
+	now := time.Now()
+
+	p.mu.RLock()
+	var uptime time.Duration
+	switch {
+	case p.stopped:
+		uptime = p.stoppedAt.Sub(p.startedAt)
+	case p.started:
+		uptime = now.Sub(p.startedAt)
+	}
+	enabled := !p.opts.DisableRateLimit
+	var tokens float64
+	if enabled {
+		tokens = p.limiter.TokensAt(now)
+	}
+	p.mu.RUnlock()
+
+	validated, _ := p.backlog()
+	m := p.metrics
+
+	return Stats{
+		Uptime:     uptime,
+		Accepted:   m.accepted.Value(),
+		Rejected:   counts(m.rejected, rejectReasons),
+		Validated:  m.validated.Value(),
+		Processed:  m.processed.Value(),
+		Failed:     counts(m.failed, stages),
+		Aggregated: m.aggregated.Value(),
+		Panics:     m.panics.Total(),
+		Queues: QueueStats{
+			Lanes:     p.LaneDepths(),
+			Validated: validated,
+			Processed: p.processedDepth(),
+			Output:    len(p.output),
+		},
+		Workers:          p.Workers(),
+		RateLimitEnabled: enabled,
+		RateLimitTokens:  tokens,
+		Throughput:       p.throughput.rate(now, uptime),
+		ThroughputWindow: p.throughput.window,
+	}
+}
+
+// counts collects the non-zero counter values for the given labels
+func counts(vec *metrics.CounterVec, labels []string) map[string]uint64 {
+	out := make(map[string]uint64)
+	for _, label := range labels {
+		// Lookup, unlike With, does not export an empty series
+		if c, ok := vec.Lookup(label); ok && c.Value() > 0 {
+			out[label] = c.Value()
+		}
+	}
+	return out
+}
+
+// throughputTracker counts completions in one-second buckets covering the
+// throughput window
+type throughputTracker struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	seconds []int64
+	counts  []uint64
+}
+
+func newThroughputTracker(window time.Duration) *throughputTracker {
+	if window <= 0 {
+		window = defaultThroughputWindow
+	}
+	n := int((window + time.Second - 1) / time.Second)
+	return &throughputTracker{
+		window:  window,
+		seconds: make([]int64, n),
+		counts:  make([]uint64, n),
+	}
+}
+
+// record counts one completion at now
+func (t *throughputTracker) record(now time.Time) {
+	sec := now.Unix()
+	i := int(sec % int64(len(t.seconds)))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.seconds[i] != sec {
+		t.seconds[i] = sec
+		t.counts[i] = 0
+	}
+	t.counts[i]++
+}
+
+// rate returns completions per second over the window ending at now, or
+// over uptime when the pipeline has been running for less than the window
+func (t *throughputTracker) rate(now time.Time, uptime time.Duration) float64 {
+	period := t.window
+	if uptime > 0 && uptime < period {
+		period = uptime
+	}
+	if period <= 0 {
+		return 0
+	}
+
+	oldest := now.Unix() - int64(len(t.seconds))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var total uint64
+	for i, sec := range t.seconds {
+		if sec > oldest {
+			total += t.counts[i]
+		}
+	}
+	return float64(total) / period.Seconds()
+}
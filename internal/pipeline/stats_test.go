@@ -0,0 +1,205 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/metrics"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestPipelineStats(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p, err := NewPipeline(Options{
+		NumWorkers:        2,
+		AggregationWindow: 2,
+		TasksPerSecond:    3,
+		BurstSize:         3,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+
+	if stats := p.Stats(); stats.Uptime != 0 {
+		t.Errorf("Expected zero uptime before Start, got %v", stats.Uptime)
+	}
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	valid := models.Task{Value: 2, Operations: []models.Operation{{Operator: models.OperatorPlus, Value: 3}}}
+	invalid := models.Task{Value: 2}
+
+	for _, task := range []models.Task{valid, valid, invalid} {
+		if err := p.AddTask(task); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := p.AddTask(valid); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-p.Results():
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for results")
+		}
+	}
+
+	stats := p.Stats()
+	if stats.Accepted != 3 {
+		t.Errorf("Expected 3 accepted, got %d", stats.Accepted)
+	}
+	if stats.Rejected["rate_limit"] != 1 {
+		t.Errorf("Expected 1 rate limit rejection, got %v", stats.Rejected)
+	}
+	if stats.Validated != 2 {
+		t.Errorf("Expected 2 validated, got %d", stats.Validated)
+	}
+	if stats.Processed != 2 {
+		t.Errorf("Expected 2 processed, got %d", stats.Processed)
+	}
+	if stats.Failed[stageValidator] != 1 {
+		t.Errorf("Expected 1 validator failure, got %v", stats.Failed)
+	}
+	if stats.Aggregated != 1 {
+		t.Errorf("Expected 1 aggregated window, got %d", stats.Aggregated)
+	}
+	if stats.Workers != 2 {
+		t.Errorf("Expected 2 workers, got %d", stats.Workers)
+	}
+	if !stats.RateLimitEnabled {
+		t.Error("Expected rate limiting to be enabled")
+	}
+	if stats.RateLimitTokens < 0 || stats.RateLimitTokens > 3 {
+		t.Errorf("Expected rate limit tokens within [0, 3], got %f", stats.RateLimitTokens)
+	}
+	if stats.Uptime <= 0 {
+		t.Errorf("Expected positive uptime, got %v", stats.Uptime)
+	}
+	if stats.Throughput <= 0 {
+		t.Errorf("Expected positive throughput, got %f", stats.Throughput)
+	}
+	if stats.ThroughputWindow != defaultThroughputWindow {
+		t.Errorf("Expected throughput window %v, got %v", defaultThroughputWindow, stats.ThroughputWindow)
+	}
+	if len(stats.Queues.Lanes) != int(models.PriorityTotalAmount) {
+		t.Errorf("Expected %d lanes, got %d", models.PriorityTotalAmount, len(stats.Queues.Lanes))
+	}
+
+	// Stats must be safe to call while the pipeline shuts down
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p.Stats()
+			}
+		}()
+	}
+	cancel()
+	wg.Wait()
+
+	for range p.Results() {
+	}
+	first := p.Stats().Uptime
+	time.Sleep(10 * time.Millisecond)
+	if second := p.Stats().Uptime; second != first {
+		t.Errorf("Expected uptime to stop after shutdown, got %v then %v", first, second)
+	}
+}
+
+func TestStatsDoesNotCreateSeries(t *testing.T) {
+	reg := metrics.NewRegistry()
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: 1,
+		TasksPerSecond:    1,
+		BurstSize:         1,
+		Metrics:           reg,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+
+	p.Stats()
+
+	var buf bytes.Buffer
+	if err := reg.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	for _, name := range []string{"pipeline_tasks_rejected_total{", "pipeline_tasks_failed_total{"} {
+		if strings.Contains(buf.String(), name) {
+			t.Errorf("Expected no %s series before any increment, got:\n%s", name, buf.String())
+		}
+	}
+}
+
+func TestThroughputTracker(t *testing.T) {
+	base := time.Unix(1000, 0)
+
+	tests := []struct {
+		name    string
+		window  time.Duration
+		records []time.Time
+		now     time.Time
+		uptime  time.Duration
+		want    float64
+	}{
+		{
+			name:    "full window",
+			window:  4 * time.Second,
+			records: []time.Time{base, base, base.Add(time.Second), base.Add(3 * time.Second)},
+			now:     base.Add(3 * time.Second),
+			uptime:  time.Minute,
+			want:    1,
+		},
+		{
+			name:    "expired buckets ignored",
+			window:  2 * time.Second,
+			records: []time.Time{base, base, base.Add(5 * time.Second)},
+			now:     base.Add(5 * time.Second),
+			uptime:  time.Minute,
+			want:    0.5,
+		},
+		{
+			name:    "uptime shorter than window",
+			window:  10 * time.Second,
+			records: []time.Time{base, base},
+			now:     base,
+			uptime:  500 * time.Millisecond,
+			want:    4,
+		},
+		{
+			name:   "no completions",
+			window: time.Second,
+			now:    base,
+			uptime: time.Minute,
+			want:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newThroughputTracker(tt.window)
+			for _, r := range tt.records {
+				tracker.record(r)
+			}
+			if got := tracker.rate(tt.now, tt.uptime); got != tt.want {
+				t.Errorf("Expected rate %f, got %f", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package pipeline
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"concurrent-pipeline-processor/pkg/models"
+
+	"golang.org/x/time/rate"
+)
+
+var (
+	// ErrTenantRateLimitExceeded is returned when a tenant exceeds its own rate limit.
+	// It matches ErrRateLimitExceeded via errors.Is.
+	ErrTenantRateLimitExceeded = &models.Error{
+		Code:  models.CodeTenantRateLimited,
+		Index: -1,
+		Err:   fmt.Errorf("tenant %w", ErrRateLimitExceeded),
+	}
+	// ErrInvalidTenantRateLimit is returned when a tenant rate limit is invalid
+	ErrInvalidTenantRateLimit = errors.New("tenant rate limit must be greater than 0")
+)
+
+const (
+	defaultTenantIdleTimeout = 5 * time.Minute
+	defaultMaxTenants        = 10000
+)
+
+// TenantLimit is the admission rate for a single tenant
+type TenantLimit struct {
+	// TasksPerSecond specifies the maximum number of tasks per second for the tenant
+	TasksPerSecond int
+	// BurstSize specifies the maximum burst for the tenant
+	BurstSize int
+}
+
+func (l TenantLimit) validate() error {
+	if l.TasksPerSecond <= 0 {
+		return ErrInvalidTenantRateLimit
+	}
+	return nil
+}
+
+func (l TenantLimit) newLimiter() *rate.Limiter {
+	burst := l.BurstSize
+	if burst < l.TasksPerSecond {
+		burst = l.TasksPerSecond
+	}
+	return rate.NewLimiter(rate.Limit(l.TasksPerSecond), burst)
+}
+
+// TenantRateLimitOptions configures per-tenant admission limits, applied in
+// addition to the global rate limit
+type TenantRateLimitOptions struct {
+	// Default is the limit for tenants without an override
+	Default TenantLimit
+	// Overrides maps tenant names to their own limits
+	Overrides map[string]TenantLimit
+	// IdleTimeout specifies how long an unused tenant limiter is kept; zero selects a default
+	IdleTimeout time.Duration
+	// MaxTenants bounds the number of tracked tenant limiters; zero selects a default
+	MaxTenants int
+}
+
+// Validate checks if the tenant rate limit options are valid
+func (o TenantRateLimitOptions) Validate() error {
+	if err := o.Default.validate(); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for tenant, limit := range o.Overrides {
+		if err := limit.validate(); err != nil {
+			return fmt.Errorf("tenant %q: %w", tenant, err)
+		}
+	}
+	return nil
+}
+
+type tenantEntry struct {
+	tenant   string
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// tenantLimiters lazily creates one limiter per tenant and evicts limiters
+// that have been idle for longer than the idle timeout. Entries are kept in
+// a list ordered by last use, most recent first, so both idle sweeps and
+// evictions only touch the entries they remove.
+type tenantLimiters struct {
+	mu        sync.Mutex
+	opts      TenantRateLimitOptions
+	entries   map[string]*list.Element
+	recent    *list.List
+	lastSweep time.Time
+}
+
+func newTenantLimiters(opts TenantRateLimitOptions) *tenantLimiters {
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = defaultTenantIdleTimeout
+	}
+	if opts.MaxTenants <= 0 {
+		opts.MaxTenants = defaultMaxTenants
+	}
+	return &tenantLimiters{
+		opts:    opts,
+		entries: make(map[string]*list.Element),
+		recent:  list.New(),
+	}
+}
+
+// reserve takes a token from the tenant's limiter. It returns nil when the
+// tenant has no token available; otherwise the caller may cancel the returned
+// reservation to hand the token back.
+func (tl *tenantLimiters) reserve(tenant string, now time.Time) *rate.Reservation {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if now.Sub(tl.lastSweep) >= tl.opts.IdleTimeout/2 {
+		tl.sweepLocked(now)
+	}
+
+	var entry *tenantEntry
+	if elem, ok := tl.entries[tenant]; ok {
+		entry = elem.Value.(*tenantEntry)
+		tl.recent.MoveToFront(elem)
+	} else {
+		if len(tl.entries) >= tl.opts.MaxTenants {
+			tl.removeLocked(tl.recent.Back())
+		}
+		limit, found := tl.opts.Overrides[tenant]
+		if !found {
+			limit = tl.opts.Default
+		}
+		entry = &tenantEntry{tenant: tenant, limiter: limit.newLimiter()}
+		tl.entries[tenant] = tl.recent.PushFront(entry)
+	}
+	entry.lastSeen = now
+
+	r := entry.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return nil
+	}
+	if r.DelayFrom(now) > 0 {
+		r.CancelAt(now)
+		return nil
+	}
+	return r
+}
+
+// len returns the number of tracked tenant limiters
+func (tl *tenantLimiters) len() int {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return len(tl.entries)
+}
+
+// sweepLocked removes the limiters idle for longer than the idle timeout,
+// starting from the least recently used
+func (tl *tenantLimiters) sweepLocked(now time.Time) {
+	tl.lastSweep = now
+	for elem := tl.recent.Back(); elem != nil; elem = tl.recent.Back() {
+		if now.Sub(elem.Value.(*tenantEntry).lastSeen) <= tl.opts.IdleTimeout {
+			return
+		}
+		tl.removeLocked(elem)
+	}
+}
+
+func (tl *tenantLimiters) removeLocked(elem *list.Element) {
+	if elem == nil {
+		return
+	}
+	delete(tl.entries, tl.recent.Remove(elem).(*tenantEntry).tenant)
+}
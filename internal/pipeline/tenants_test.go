@@ -0,0 +1,230 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestTenantLimiters(t *testing.T) {
+	t.Run("applies default and override limits", func(t *testing.T) {
+		tl := newTenantLimiters(TenantRateLimitOptions{
+			Default: TenantLimit{TasksPerSecond: 1, BurstSize: 1},
+			Overrides: map[string]TenantLimit{
+				"vip": {TasksPerSecond: 1, BurstSize: 3},
+			},
+		})
+		now := time.Now()
+
+		admitted := map[string]int{}
+		for _, tenant := range []string{"vip", "other"} {
+			for i := 0; i < 5; i++ {
+				if tl.reserve(tenant, now) != nil {
+					admitted[tenant]++
+				}
+			}
+		}
+
+		if admitted["vip"] != 3 {
+			t.Errorf("Expected 3 admitted for vip, got %d", admitted["vip"])
+		}
+		if admitted["other"] != 1 {
+			t.Errorf("Expected 1 admitted for other, got %d", admitted["other"])
+		}
+	})
+
+	t.Run("cancelled reservation returns token", func(t *testing.T) {
+		tl := newTenantLimiters(TenantRateLimitOptions{
+			Default: TenantLimit{TasksPerSecond: 1, BurstSize: 1},
+		})
+		now := time.Now()
+
+		r := tl.reserve("a", now)
+		if r == nil {
+			t.Fatal("Expected first reservation to succeed")
+		}
+		r.CancelAt(now)
+
+		if tl.reserve("a", now) == nil {
+			t.Error("Expected token to be available after cancel")
+		}
+	})
+
+	t.Run("evicts idle limiters", func(t *testing.T) {
+		tl := newTenantLimiters(TenantRateLimitOptions{
+			Default:     TenantLimit{TasksPerSecond: 1},
+			IdleTimeout: time.Minute,
+		})
+		now := time.Now()
+
+		tl.reserve("a", now)
+		tl.reserve("b", now)
+		if got := tl.len(); got != 2 {
+			t.Fatalf("Expected 2 limiters, got %d", got)
+		}
+
+		tl.reserve("c", now.Add(2*time.Minute))
+		if got := tl.len(); got != 1 {
+			t.Errorf("Expected idle limiters to be evicted, got %d", got)
+		}
+	})
+
+	t.Run("bounds tracked tenants", func(t *testing.T) {
+		tl := newTenantLimiters(TenantRateLimitOptions{
+			Default:    TenantLimit{TasksPerSecond: 1},
+			MaxTenants: 2,
+		})
+		now := time.Now()
+
+		tl.reserve("a", now)
+		tl.reserve("b", now.Add(time.Millisecond))
+		tl.reserve("c", now.Add(2*time.Millisecond))
+
+		if got := tl.len(); got != 2 {
+			t.Errorf("Expected 2 limiters, got %d", got)
+		}
+		if _, ok := tl.entries["a"]; ok {
+			t.Error("Expected least recently seen tenant to be evicted")
+		}
+	})
+
+	t.Run("evicts the least recently used tenant", func(t *testing.T) {
+		tl := newTenantLimiters(TenantRateLimitOptions{
+			Default:    TenantLimit{TasksPerSecond: 1},
+			MaxTenants: 2,
+		})
+		now := time.Now()
+
+		tl.reserve("a", now)
+		tl.reserve("b", now)
+		tl.reserve("a", now)
+		tl.reserve("c", now)
+
+		if _, ok := tl.entries["b"]; ok {
+			t.Error("Expected b to be evicted")
+		}
+		if _, ok := tl.entries["a"]; !ok {
+			t.Error("Expected recently used a to be kept")
+		}
+	})
+}
+
+func TestTenantRateLimitOptionsValidate(t *testing.T) {
+	opts := TenantRateLimitOptions{Default: TenantLimit{TasksPerSecond: 0}}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidTenantRateLimit) {
+		t.Errorf("Expected ErrInvalidTenantRateLimit for default, got %v", err)
+	}
+
+	opts = TenantRateLimitOptions{
+		Default:   TenantLimit{TasksPerSecond: 1},
+		Overrides: map[string]TenantLimit{"bad": {}},
+	}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidTenantRateLimit) {
+		t.Errorf("Expected ErrInvalidTenantRateLimit for override, got %v", err)
+	}
+}
+
+func TestTenantRateLimiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p, err := NewPipeline(Options{
+		NumWorkers:        2,
+		AggregationWindow: 100,
+		TasksPerSecond:    100,
+		BurstSize:         200,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+		TenantRateLimit: &TenantRateLimitOptions{
+			Default: TenantLimit{TasksPerSecond: 1, BurstSize: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	noisy := models.Task{Value: 1, Operations: []models.Operation{}, Tenant: "noisy"}
+	quiet := models.Task{Value: 1, Operations: []models.Operation{}, Tenant: "quiet"}
+
+	for i := 0; i < 2; i++ {
+		if err := p.AddTask(noisy); err != nil {
+			t.Fatalf("Failed to add noisy task %d: %v", i, err)
+		}
+	}
+
+	err = p.AddTask(noisy)
+	if !errors.Is(err, ErrTenantRateLimitExceeded) {
+		t.Errorf("Expected ErrTenantRateLimitExceeded, got %v", err)
+	}
+	if !errors.Is(err, ErrRateLimitExceeded) {
+		t.Error("Expected tenant error to match ErrRateLimitExceeded")
+	}
+
+	// Another tenant is unaffected by the noisy one
+	if err := p.AddTask(quiet); err != nil {
+		t.Errorf("Expected quiet tenant to be admitted, got %v", err)
+	}
+}
+
+func TestTenantTokenReturnedWhenLaneFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const burst = 6
+
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: 10,
+		TasksPerSecond:    100,
+		BurstSize:         200,
+		InputBufferSize:   1,
+		ResultBufferSize:  10,
+		TenantRateLimit: &TenantRateLimitOptions{
+			Default: TenantLimit{TasksPerSecond: 1, BurstSize: burst},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+
+	// Hold the validator so the lane fills up
+	release := make(chan struct{})
+	var once sync.Once
+	defer once.Do(func() { close(release) })
+	p.(*pipeline).validate = func(models.Task) error {
+		<-release
+		return nil
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	task := models.Task{Value: 1, Operations: []models.Operation{}, Tenant: "a"}
+	admitted := 0
+	for ; admitted < burst; admitted++ {
+		if err := p.AddTask(task); err != nil {
+			if !errors.Is(err, ErrBufferFull) {
+				t.Fatalf("Expected ErrBufferFull, got %v", err)
+			}
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if admitted == burst {
+		t.Fatal("Expected the lane to fill up before the tenant's burst")
+	}
+
+	// Rejected tasks must not use up the tenant's tokens
+	for i := 0; i < burst; i++ {
+		if err := p.AddTask(task); !errors.Is(err, ErrBufferFull) {
+			t.Fatalf("Expected ErrBufferFull on attempt %d, got %v", i, err)
+		}
+	}
+}
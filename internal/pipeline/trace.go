@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"concurrent-pipeline-processor/internal/tracing"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// Span names, one per stage a task passes through
+const (
+	spanAdmit     = "pipeline.admit"
+	spanValidate  = "pipeline.validate"
+	spanProcess   = "pipeline.process"
+	spanAggregate = "pipeline.aggregate"
+	spanWindow    = "pipeline.window"
+)
+
+// job is a task travelling between stages together with the span context of
+// its admission, which parents the task's stage spans
+type job struct {
+	task  models.Task
+	trace tracing.SpanContext
+}
+
+// outcome is a processed result travelling to the aggregator
+type outcome struct {
+	result models.Result
+	trace  tracing.SpanContext
+}
+
+// startSpan starts a stage span as a child of parent
+func (p *pipeline) startSpan(parent tracing.SpanContext, name string) *tracing.Span {
+	_, span := p.opts.Tracer.Start(tracing.ContextWithSpanContext(context.Background(), parent), name)
+	return span
+}
+
+// windowTrace collects the aggregate spans of the tasks in the open window so
+// the window span can link to them. It is only used by the aggregator.
+type windowTrace struct {
+	tracer *tracing.Tracer
+	links  []tracing.SpanContext
+	starts []time.Time
+}
+
+func newWindowTrace(tracer *tracing.Tracer) *windowTrace {
+	return &windowTrace{tracer: tracer}
+}
+
+// add records a task added to the open window
+func (w *windowTrace) add(sc tracing.SpanContext, start time.Time) {
+	if w.tracer == nil {
+		return
+	}
+	w.links = append(w.links, sc)
+	w.starts = append(w.starts, start)
+}
+
+// emit records the window span for a result emitted by the aggregator for a
+// window: its sum, or the error summing it failed with. Windows are emitted
+// in order, so the result covers the oldest tasks up to the window size; a
+// flushed partial window covers fewer.
+func (w *windowTrace) emit(size int, result models.Result) {
+	if w.tracer == nil || len(w.links) == 0 {
+		return
+	}
+	n := min(size, len(w.links))
+
+	_, span := w.tracer.StartAt(context.Background(), spanWindow, w.starts[0])
+	span.SetAttributes(tracing.Int("window.size", n))
+	if result.Error != nil {
+		span.RecordError(result.Error)
+	} else {
+		span.SetAttributes(tracing.String("window.sum", result.Exact()))
+	}
+	for _, sc := range w.links[:n] {
+		span.AddLink(sc)
+	}
+	span.End()
+
+	w.links = w.links[n:]
+	w.starts = w.starts[n:]
+}
@@ -0,0 +1,279 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/internal/tracing"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// spanRecorder is an exporter keeping spans in memory
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(span tracing.SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+func (r *spanRecorder) byName() map[string][]tracing.SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[string][]tracing.SpanData)
+	for _, s := range r.spans {
+		out[s.Name] = append(out[s.Name], s)
+	}
+	return out
+}
+
+func TestPipelineTracing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec := &spanRecorder{}
+	tracer := tracing.NewTracer(rec, nil)
+
+	p, err := NewPipeline(Options{
+		NumWorkers:        2,
+		AggregationWindow: 2,
+		TasksPerSecond:    100,
+		BurstSize:         100,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+		Tracer:            tracer,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	reqCtx, request := tracer.Start(context.Background(), "request")
+	for _, v := range []int{1, 2} {
+		task := models.Task{Value: v, Operations: []models.Operation{{Operator: models.OperatorPlus, Value: 1}}}
+		if err := p.AddTaskContext(reqCtx, task); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	request.End()
+
+	select {
+	case result := <-p.Results():
+		if result.Result != 5 {
+			t.Errorf("Expected result 5, got %d", result.Result)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for result")
+	}
+
+	spans := rec.byName()
+	for _, name := range []string{spanAdmit, spanValidate, spanProcess, spanAggregate} {
+		if len(spans[name]) != 2 {
+			t.Fatalf("Expected 2 %s spans, got %d", name, len(spans[name]))
+		}
+	}
+	if len(spans[spanWindow]) != 1 {
+		t.Fatalf("Expected 1 %s span, got %d", spanWindow, len(spans[spanWindow]))
+	}
+
+	traceID := request.SpanContext().TraceID
+	admits := make(map[tracing.SpanID]bool)
+	for _, s := range spans[spanAdmit] {
+		if s.Parent != request.SpanContext().SpanID {
+			t.Errorf("Expected admit span parent %s, got %s", request.SpanContext().SpanID, s.Parent)
+		}
+		admits[s.SpanContext.SpanID] = true
+	}
+	for _, name := range []string{spanValidate, spanProcess, spanAggregate} {
+		for _, s := range spans[name] {
+			if s.SpanContext.TraceID != traceID {
+				t.Errorf("Expected %s span in trace %s, got %s", name, traceID, s.SpanContext.TraceID)
+			}
+			if !admits[s.Parent] {
+				t.Errorf("Expected %s span to be a child of an admit span", name)
+			}
+		}
+	}
+
+	window := spans[spanWindow][0]
+	if len(window.Links) != 2 {
+		t.Fatalf("Expected window span to link 2 tasks, got %d", len(window.Links))
+	}
+	aggregates := make(map[tracing.SpanContext]bool)
+	for _, s := range spans[spanAggregate] {
+		aggregates[s.SpanContext] = true
+	}
+	for _, link := range window.Links {
+		if !aggregates[link] {
+			t.Errorf("Expected window link %v to point at an aggregate span", link)
+		}
+	}
+}
+
+func TestPipelineTracingWindowAfterInterceptors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec := &spanRecorder{}
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: 2,
+		TasksPerSecond:    100,
+		BurstSize:         100,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+		Mode:              processor.ModeBig,
+		Tracer:            tracing.NewTracer(rec, nil),
+		Interceptors: Interceptors{
+			Aggregator: []AggregatorInterceptor{
+				// Drop the second task's result
+				func(ctx context.Context, result models.Result, next AggregateFunc) {
+					if result.Exact() != "2" {
+						next(ctx, result)
+					}
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	for _, v := range []int{1, 2, 3} {
+		if err := p.AddTask(models.Task{Value: v, Operations: []models.Operation{}}); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+
+	select {
+	case result := <-p.Results():
+		if got := result.Exact(); got != "4" {
+			t.Errorf("Expected result 4, got %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for result")
+	}
+
+	spans := rec.byName()
+	aggregates, windows := spans[spanAggregate], spans[spanWindow]
+	if len(aggregates) != 3 || len(windows) != 1 {
+		t.Fatalf("Expected 3 aggregate spans and 1 window span, got %d and %d", len(aggregates), len(windows))
+	}
+
+	window := windows[0]
+	want := []tracing.SpanContext{aggregates[0].SpanContext, aggregates[2].SpanContext}
+	if len(window.Links) != len(want) || window.Links[0] != want[0] || window.Links[1] != want[1] {
+		t.Errorf("Expected window links %v, got %v", want, window.Links)
+	}
+	sum := tracing.String("window.sum", "4")
+	found := false
+	for _, attr := range window.Attributes {
+		found = found || attr == sum
+	}
+	if !found {
+		t.Errorf("Expected attribute %v, got %v", sum, window.Attributes)
+	}
+}
+
+func TestPipelineTracingFailedWindow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec := &spanRecorder{}
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: 2,
+		TasksPerSecond:    100,
+		BurstSize:         100,
+		InputBufferSize:   100,
+		ResultBufferSize:  100,
+		Mode:              processor.ModeFloat,
+		Tracer:            tracing.NewTracer(rec, nil),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	// The first window's sum overflows to infinity
+	for _, v := range []float64{1e308, 1e308, 2, 3} {
+		if err := p.AddTask(models.Task{Float: &v, Operations: []models.Operation{}}); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+
+	for _, wantErr := range []bool{true, false} {
+		select {
+		case result := <-p.Results():
+			if (result.Error != nil) != wantErr {
+				t.Fatalf("Expected error %v, got result %+v", wantErr, result)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for result")
+		}
+	}
+
+	spans := rec.byName()
+	aggregates, windows := spans[spanAggregate], spans[spanWindow]
+	if len(aggregates) != 4 || len(windows) != 2 {
+		t.Fatalf("Expected 4 aggregate spans and 2 window spans, got %d and %d", len(aggregates), len(windows))
+	}
+
+	failed, summed := windows[0], windows[1]
+	if failed.Status != tracing.StatusError {
+		t.Errorf("Expected the failed window span to have error status, got %d", failed.Status)
+	}
+	if len(failed.Links) != 2 || failed.Links[0] != aggregates[0].SpanContext || failed.Links[1] != aggregates[1].SpanContext {
+		t.Errorf("Expected the failed window to link the first two tasks, got %v", failed.Links)
+	}
+	if len(summed.Links) != 2 || summed.Links[0] != aggregates[2].SpanContext || summed.Links[1] != aggregates[3].SpanContext {
+		t.Errorf("Expected the next window to link the last two tasks, got %v", summed.Links)
+	}
+	sum := tracing.String("window.sum", "5")
+	if !slices.Contains(summed.Attributes, sum) {
+		t.Errorf("Expected attribute %v, got %v", sum, summed.Attributes)
+	}
+}
+
+func TestPipelineTracingRejectedTask(t *testing.T) {
+	rec := &spanRecorder{}
+	p, err := NewPipeline(Options{
+		NumWorkers:        1,
+		AggregationWindow: 1,
+		TasksPerSecond:    1,
+		Tracer:            tracing.NewTracer(rec, nil),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pipeline: %v", err)
+	}
+
+	if err := p.AddTask(models.Task{}); !errors.Is(err, ErrPipelineNotStarted) {
+		t.Fatalf("Expected ErrPipelineNotStarted, got %v", err)
+	}
+
+	admits := rec.byName()[spanAdmit]
+	if len(admits) != 1 {
+		t.Fatalf("Expected 1 admit span, got %d", len(admits))
+	}
+	if admits[0].Status != tracing.StatusError {
+		t.Errorf("Expected rejected admit span to have error status, got %d", admits[0].Status)
+	}
+}
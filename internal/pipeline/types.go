@@ -3,7 +3,11 @@ package pipeline
 import (
 	"context"
 	"errors"
+	"time"
 
+	"concurrent-pipeline-processor/internal/metrics"
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/internal/tracing"
 	"concurrent-pipeline-processor/pkg/models"
 )
 
@@ -14,6 +18,36 @@ var (
 	ErrInvalidAggregationWindow = errors.New("aggregation window must be greater than 0")
 	// ErrInvalidRateLimit is returned when the rate limit is invalid
 	ErrInvalidRateLimit = errors.New("rate limit must be greater than 0")
+	// ErrInvalidThroughputWindow is returned when the throughput window is negative
+	ErrInvalidThroughputWindow = errors.New("throughput window must not be negative")
+	// ErrExplainErrorsMode is returned when errors are to be explained in a
+	// mode other than processor.ModeInt
+	ErrExplainErrorsMode = errors.New("explaining errors requires int arithmetic")
+	// ErrInvalidProgramCacheSize is returned when the program cache size is negative
+	ErrInvalidProgramCacheSize = errors.New("program cache size must not be negative")
+	// ErrProgramCacheMode is returned when programs are to be cached in a
+	// mode other than processor.ModeInt
+	ErrProgramCacheMode = errors.New("caching programs requires int arithmetic")
+)
+
+// ErrorPolicy controls how a processor worker reacts to a recovered panic
+type ErrorPolicy int
+
+const (
+	// ErrorPolicyContinue keeps the worker goroutine running after a recovered panic
+	ErrorPolicyContinue ErrorPolicy = iota
+	// ErrorPolicyRestart replaces the worker goroutine with a fresh one after a recovered panic
+	ErrorPolicyRestart
+)
+
+// PausePolicy controls how AddTask treats tasks while the pipeline is paused
+type PausePolicy int
+
+const (
+	// PausePolicyBuffer keeps accepting tasks into the buffers while paused
+	PausePolicyBuffer PausePolicy = iota
+	// PausePolicyReject rejects tasks with ErrPipelinePaused while paused
+	PausePolicyReject
 )
 
 // Pipeline represents the main interface for the concurrent pipeline processor
@@ -22,8 +56,47 @@ type Pipeline interface {
 	Start(ctx context.Context) error
 	// AddTask adds a new task to the pipeline
 	AddTask(task models.Task) error
+	// AddTaskContext adds a new task to the pipeline; the task's spans are
+	// recorded as children of the span carried by ctx, if any
+	AddTaskContext(ctx context.Context, task models.Task) error
 	// Results returns a channel for receiving processed results
 	Results() <-chan models.Result
+	// SetWorkers grows or shrinks the processor worker pool to n workers.
+	// Workers removed by a shrink finish their current task before exiting.
+	SetWorkers(n int) error
+	// Workers returns the number of running processor workers
+	Workers() int
+	// SetRateLimit changes the admission rate and burst size, re-enabling
+	// rate limiting if it was disabled
+	SetRateLimit(tasksPerSecond, burst int) error
+	// DisableRateLimit admits tasks without rate limiting until SetRateLimit is called
+	DisableRateLimit()
+	// RateLimit returns the configured admission rate, burst size and whether limiting is enabled
+	RateLimit() (tasksPerSecond, burst int, enabled bool)
+	// LaneDepths returns the number of queued tasks in each priority lane
+	LaneDepths() map[models.Priority]int
+	// Stats returns a point-in-time snapshot of the pipeline counters and queues
+	Stats() Stats
+	// PauseIntake makes AddTask reject tasks with ErrIntakePaused until
+	// ResumeIntake is called; queued tasks are still processed
+	PauseIntake()
+	// ResumeIntake accepts tasks again after PauseIntake
+	ResumeIntake()
+	// IntakePaused reports whether intake is paused
+	IntakePaused() bool
+	// Pause stops the processor workers from taking validated tasks until
+	// Resume is called; tasks being processed finish. Depending on
+	// Options.PausePolicy, new tasks are buffered or rejected meanwhile.
+	Pause() error
+	// Resume lets the processor workers take tasks again after Pause
+	Resume()
+	// State returns the lifecycle stage of the pipeline
+	State() State
+	// Drain stops accepting tasks and waits until every queued task has been
+	// processed and the results channel is closed, or ctx is done
+	Drain(ctx context.Context) error
+	// Ready reports whether the pipeline is started and neither draining nor stopped
+	Ready() bool
 }
 
 // Options contains configuration options for the pipeline
@@ -36,10 +109,54 @@ type Options struct {
 	TasksPerSecond int
 	// BurstSize specifies the maximum number of tasks that can be processed in a burst
 	BurstSize int
-	// InputBufferSize specifies the size of the input channel buffer
+	// DisableRateLimit admits tasks without rate limiting
+	DisableRateLimit bool
+	// TenantRateLimit enables per-tenant rate limits keyed by Task.Tenant when set
+	TenantRateLimit *TenantRateLimitOptions
+	// FairQueue enables weighted fair scheduling across tenants between the
+	// validator and the processor workers when set
+	FairQueue *FairQueueOptions
+	// InputBufferSize specifies the size of each priority lane buffer
 	InputBufferSize int
+	// LaneWeights specifies the relative share of scheduling slots per priority
+	// lane; missing or zero entries select the defaults (high 8, normal 4, low 1)
+	LaneWeights map[models.Priority]int
+	// MaxLaneSkips specifies how many times in a row a non-empty lane may be
+	// passed over before it is served regardless of weight; zero selects a default
+	MaxLaneSkips int
 	// ResultBufferSize specifies the size of the result channel buffer
 	ResultBufferSize int
+	// ErrorPolicy specifies what happens to a processor worker after it recovers from a panic
+	ErrorPolicy ErrorPolicy
+	// PausePolicy specifies whether tasks are buffered or rejected while the pipeline is paused
+	PausePolicy PausePolicy
+	// Metrics is the registry the pipeline instruments are registered with;
+	// nil keeps them in a private registry. A registry serves one pipeline.
+	Metrics *metrics.Registry
+	// ThroughputWindow specifies the period Stats reports throughput over;
+	// zero selects a default of 10 seconds
+	ThroughputWindow time.Duration
+	// Autoscale enables automatic resizing of the processor worker pool when set
+	Autoscale *AutoscaleOptions
+	// Tracer records a span per task for each stage when set
+	Tracer *tracing.Tracer
+	// Interceptors wrap the validator, processor and aggregator stage functions
+	Interceptors Interceptors
+	// ValidationRules are checked by the validator stage on top of the built-in checks
+	ValidationRules processor.Rules
+	// Mode selects the arithmetic tasks are processed and aggregated with; the
+	// zero value is processor.ModeInt
+	Mode processor.Mode
+	// Decimal sets the scale and rounding of processor.ModeDecimal
+	Decimal processor.DecimalOptions
+	// ExplainErrors attaches an explanation of the operations applied before
+	// the failure to processor error results; only processor.ModeInt
+	// supports it, and Validate rejects it in other modes
+	ExplainErrors bool
+	// ProgramCacheSize processes tasks with programs compiled once per
+	// operation list, keeping this many in a processor.ProgramCache, when
+	// greater than 0; only processor.ModeInt supports it
+	ProgramCacheSize int
 }
 
 // Validate checks if the options are valid
@@ -53,6 +170,52 @@ func (o Options) Validate() error {
 	if o.TasksPerSecond <= 0 {
 		return ErrInvalidRateLimit
 	}
+	if o.ThroughputWindow < 0 {
+		return ErrInvalidThroughputWindow
+	}
+	for _, w := range o.LaneWeights {
+		if w < 0 {
+			return ErrInvalidLaneWeight
+		}
+	}
+	if o.TenantRateLimit != nil {
+		if err := o.TenantRateLimit.Validate(); err != nil {
+			return err
+		}
+	}
+	if o.FairQueue != nil {
+		if err := o.FairQueue.Validate(); err != nil {
+			return err
+		}
+	}
+	if o.Autoscale != nil {
+		if err := o.Autoscale.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := o.Mode.Validate(); err != nil {
+		return err
+	}
+	if o.Mode == processor.ModeDecimal {
+		if err := o.Decimal.Validate(); err != nil {
+			return err
+		}
+	}
+	if o.ExplainErrors && o.Mode != processor.ModeInt {
+		return ErrExplainErrorsMode
+	}
+	if o.ProgramCacheSize < 0 {
+		return ErrInvalidProgramCacheSize
+	}
+	if o.ProgramCacheSize > 0 && o.Mode != processor.ModeInt {
+		return ErrProgramCacheMode
+	}
+	return o.ValidationRules.Validate()
+}
+
+// withDefaults returns the options with the burst and buffer sizes left
+// unset replaced by their defaults
+func (o Options) withDefaults() Options {
 	if o.BurstSize < o.TasksPerSecond {
 		o.BurstSize = o.TasksPerSecond
 	}
@@ -62,5 +225,5 @@ func (o Options) Validate() error {
 	if o.ResultBufferSize <= 0 {
 		o.ResultBufferSize = o.NumWorkers * o.AggregationWindow * 2
 	}
-	return nil
+	return o
 }
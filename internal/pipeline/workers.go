@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"concurrent-pipeline-processor/internal/tracing"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// processJob is the processor worker function. A panic while processing a
+// task is returned as the error, so the worker is replaced under
+// ErrorPolicyRestart.
+func (p *pipeline) processJob(ctx context.Context, j job) (outcome, error) {
+	span := p.startSpan(j.trace, spanProcess)
+	ctx = tracing.ContextWithSpanContext(ctx, span.SpanContext())
+	var result models.Result
+	start := time.Now()
+	perr := p.guard(stageProcessor, &j.task, func() { result = p.processChain(ctx, j.task) })
+	p.latency.observe(time.Since(start))
+	p.metrics.observeStage(stageProcessor, start)
+	if perr != nil {
+		result = models.Result{Error: perr}
+	}
+	if result.Error != nil {
+		p.metrics.failed.With(stageProcessor).Inc()
+	} else {
+		p.metrics.processed.Inc()
+		p.throughput.record(time.Now())
+	}
+	span.RecordError(result.Error)
+	span.End()
+	return outcome{result: result, trace: j.trace}, perr
+}
+
+// failJob reports a task whose processing panicked as an error result
+func (p *pipeline) failJob(_ context.Context, j job, err error) (outcome, bool) {
+	return outcome{result: models.Result{Error: err}, trace: j.trace}, true
+}
+
+// processedDepth returns the number of processed results waiting for the aggregator
+func (p *pipeline) processedDepth() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.processed)
+}
+
+// backlog returns the number of validated tasks waiting for a worker and the
+// capacity available to hold them
+func (p *pipeline) backlog() (queued, capacity int) {
+	p.mu.RLock()
+	validated := p.validated
+	p.mu.RUnlock()
+
+	queued, capacity = len(validated), cap(validated)
+	if fq := p.opts.FairQueue; fq != nil {
+		queued += int(p.fairQueued.Load())
+		if fq.Capacity > 0 {
+			capacity += fq.Capacity
+		} else {
+			capacity += p.opts.InputBufferSize
+		}
+	}
+	return queued, capacity
+}
@@ -0,0 +1,139 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"concurrent-pipeline-processor/internal/processor"
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestSetWorkers(t *testing.T) {
+	const numTasks = 8
+
+	newPipeline := func(t *testing.T, workers int) *pipeline {
+		p, err := NewPipeline(Options{
+			NumWorkers:        workers,
+			AggregationWindow: numTasks,
+			TasksPerSecond:    100,
+			BurstSize:         200,
+			InputBufferSize:   100,
+			ResultBufferSize:  100,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pipeline: %v", err)
+		}
+		return p.(*pipeline)
+	}
+
+	waitForWorkers := func(t *testing.T, p *pipeline, want int) {
+		deadline := time.Now().Add(2 * time.Second)
+		for p.Workers() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d workers, got %d", want, p.Workers())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	t.Run("rejects invalid worker count", func(t *testing.T) {
+		p := newPipeline(t, 2)
+		if err := p.SetWorkers(0); err != ErrInvalidNumWorkers {
+			t.Errorf("Expected ErrInvalidNumWorkers, got %v", err)
+		}
+	})
+
+	t.Run("applies count set before start", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := newPipeline(t, 2)
+		if err := p.SetWorkers(5); err != nil {
+			t.Fatalf("SetWorkers() error = %v", err)
+		}
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+		waitForWorkers(t, p, 5)
+	})
+
+	t.Run("grows and shrinks running pool", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := newPipeline(t, 2)
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+		waitForWorkers(t, p, 2)
+
+		if err := p.SetWorkers(6); err != nil {
+			t.Fatalf("SetWorkers() error = %v", err)
+		}
+		waitForWorkers(t, p, 6)
+
+		if err := p.SetWorkers(1); err != nil {
+			t.Fatalf("SetWorkers() error = %v", err)
+		}
+		waitForWorkers(t, p, 1)
+	})
+
+	t.Run("shrink keeps in-flight tasks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := newPipeline(t, 4)
+		release := make(chan struct{})
+		p.process = func(task models.Task) models.Result {
+			<-release
+			return processor.ProcessTask(task)
+		}
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+
+		for i := 0; i < numTasks; i++ {
+			task := models.Task{Value: 1, Operations: []models.Operation{}}
+			if err := p.AddTask(task); err != nil {
+				t.Fatalf("Failed to add task: %v", err)
+			}
+		}
+
+		// Let the workers pick up tasks, then shrink while they are busy
+		time.Sleep(50 * time.Millisecond)
+		if err := p.SetWorkers(1); err != nil {
+			t.Fatalf("SetWorkers() error = %v", err)
+		}
+		close(release)
+
+		// Every task must contribute to the single aggregated window
+		select {
+		case result := <-p.Results():
+			if result.Error != nil {
+				t.Fatalf("Unexpected error: %v", result.Error)
+			}
+			if result.Result != numTasks {
+				t.Errorf("Expected sum %d, got %d", numTasks, result.Result)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for aggregated result")
+		}
+		waitForWorkers(t, p, 1)
+	})
+
+	t.Run("rejects resize after stop", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		p := newPipeline(t, 2)
+		if err := p.Start(ctx); err != nil {
+			t.Fatalf("Failed to start pipeline: %v", err)
+		}
+		cancel()
+		time.Sleep(100 * time.Millisecond)
+
+		if err := p.SetWorkers(3); err != ErrPipelineStopped {
+			t.Errorf("Expected ErrPipelineStopped, got %v", err)
+		}
+	})
+}
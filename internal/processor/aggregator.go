@@ -2,6 +2,7 @@ package processor
 
 import (
 	"concurrent-pipeline-processor/pkg/models"
+	"math/big"
 	"time"
 )
 
@@ -64,16 +65,89 @@ func (a *Aggregator) flush() {
 		return
 	}
 
-	sum := 0
-	for _, r := range a.buffer {
-		sum += r.Result
-	}
-
 	select {
-	case a.aggregate <- models.Result{Result: sum}:
+	case a.aggregate <- sum(a.buffer):
 		a.buffer = a.buffer[:0]
 	case <-time.After(time.Second):
 		// If we can't send after timeout, this is a serious issue
 		panic("aggregator blocked for too long")
 	}
 }
+
+// sum adds up the results of a window, exactly if any of them was computed
+// in arbitrary precision or decimal mode, and with compensated summation if
+// any was computed in float mode
+func sum(results []models.Result) models.Result {
+	exact, float, scale := false, false, -1
+	for _, r := range results {
+		if r.Big != nil {
+			exact = true
+		}
+		if r.Float != nil {
+			float = true
+		}
+		if r.Decimal != nil && r.Decimal.Scale > scale {
+			scale = r.Decimal.Scale
+		}
+	}
+
+	if float {
+		return sumFloat(results)
+	}
+	if scale >= 0 {
+		return sumDecimal(results, scale)
+	}
+	if !exact {
+		total := 0
+		for _, r := range results {
+			total += r.Result
+		}
+		return models.Result{Result: total}
+	}
+
+	total := new(big.Int)
+	for _, r := range results {
+		if r.Big != nil {
+			total.Add(total, r.Big)
+		} else {
+			total.Add(total, big.NewInt(int64(r.Result)))
+		}
+	}
+	return models.BigResult(total)
+}
+
+// sumDecimal adds up results at the given scale; every decimal result has
+// at most that scale, so the sum is exact
+func sumDecimal(results []models.Result, scale int) models.Result {
+	total := new(big.Int)
+	unit := models.Pow10(scale)
+	for _, r := range results {
+		switch {
+		case r.Decimal != nil:
+			total.Add(total, r.Decimal.Rescale(scale).Unscaled)
+		case r.Big != nil:
+			total.Add(total, new(big.Int).Mul(r.Big, unit))
+		default:
+			total.Add(total, new(big.Int).Mul(big.NewInt(int64(r.Result)), unit))
+		}
+	}
+	return models.DecimalResult(models.Decimal{Unscaled: total, Scale: scale})
+}
+
+// sumFloat adds up results as float64 values; a sum that is no longer finite
+// is reported as an error
+func sumFloat(results []models.Result) models.Result {
+	values := make([]float64, len(results))
+	for i, r := range results {
+		if r.Float != nil {
+			values[i] = *r.Float
+		} else {
+			values[i] = float64(r.Result)
+		}
+	}
+	total := kahanSum(values)
+	if err := checkFinite(total); err != nil {
+		return models.Result{Error: models.WrapError(err, models.StageAggregator, "", -1)}
+	}
+	return models.FloatResult(total)
+}
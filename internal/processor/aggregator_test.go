@@ -1,6 +1,9 @@
 package processor
 
 import (
+	"errors"
+	"math"
+	"math/big"
 	"testing"
 	"time"
 
@@ -30,6 +33,85 @@ func TestAggregator(t *testing.T) {
 		}
 	})
 
+	t.Run("sums exactly in arbitrary precision mode", func(t *testing.T) {
+		agg := NewAggregator(2)
+		defer agg.Close()
+
+		large, _ := new(big.Int).SetString("100000000000000000000", 10)
+		go func() {
+			agg.Add(models.BigResult(large))
+			agg.Add(models.BigResult(big.NewInt(5)))
+		}()
+
+		select {
+		case result := <-agg.Results():
+			if got := result.Exact(); got != "100000000000000000005" {
+				t.Errorf("Expected sum 100000000000000000005, got %s", got)
+			}
+		case <-time.After(time.Second):
+			t.Error("Timeout waiting for aggregated result")
+		}
+	})
+
+	t.Run("sums decimals exactly", func(t *testing.T) {
+		agg := NewAggregator(3)
+		defer agg.Close()
+
+		go func() {
+			agg.Add(models.DecimalResult(models.Decimal{Unscaled: big.NewInt(10), Scale: 2}))
+			agg.Add(models.DecimalResult(models.Decimal{Unscaled: big.NewInt(20), Scale: 2}))
+			agg.Add(models.DecimalResult(models.Decimal{Unscaled: big.NewInt(1), Scale: 1}))
+		}()
+
+		select {
+		case result := <-agg.Results():
+			if got := result.Exact(); got != "0.40" { // 0.10 + 0.20 + 0.1
+				t.Errorf("Expected sum 0.40, got %s", got)
+			}
+		case <-time.After(time.Second):
+			t.Error("Timeout waiting for aggregated result")
+		}
+	})
+
+	t.Run("sums floats with compensation", func(t *testing.T) {
+		agg := NewAggregator(3)
+		defer agg.Close()
+
+		go func() {
+			agg.Add(models.FloatResult(1e16))
+			agg.Add(models.FloatResult(1))
+			agg.Add(models.FloatResult(-1e16))
+		}()
+
+		select {
+		case result := <-agg.Results():
+			if got := result.Exact(); got != "1" {
+				t.Errorf("Expected sum 1, got %s", got)
+			}
+		case <-time.After(time.Second):
+			t.Error("Timeout waiting for aggregated result")
+		}
+	})
+
+	t.Run("reports an infinite float sum", func(t *testing.T) {
+		agg := NewAggregator(2)
+		defer agg.Close()
+
+		go func() {
+			agg.Add(models.FloatResult(math.MaxFloat64))
+			agg.Add(models.FloatResult(math.MaxFloat64))
+		}()
+
+		select {
+		case result := <-agg.Results():
+			if !errors.Is(result.Error, ErrInfinity) {
+				t.Errorf("Expected ErrInfinity, got %v", result.Error)
+			}
+		case <-time.After(time.Second):
+			t.Error("Timeout waiting for aggregated result")
+		}
+	})
+
 	t.Run("handles error results", func(t *testing.T) {
 		agg := NewAggregator(3)
 		defer agg.Close()
@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"math/big"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// ProcessTaskBig processes a task like ProcessTask using arbitrary-precision
+// integers. Division truncates towards zero as in ProcessTask, and the exact
+// value is returned in Result.Big.
+func ProcessTaskBig(task models.Task) models.Result {
+	result := big.NewInt(int64(task.Value))
+
+	operand := new(big.Int)
+	for i, op := range task.Operations {
+		var err error
+		exit := false
+		if op.IsControl() {
+			result, exit, err = control(bigNumber{}, result, op)
+		} else {
+			operand.SetInt64(int64(op.Value))
+			err = applyBig(result, operand, op.Operator)
+		}
+		if err != nil {
+			return models.Result{Error: models.WrapError(err, models.StageProcessor, task.ID, i)}
+		}
+		if exit {
+			break
+		}
+	}
+
+	return models.BigResult(result)
+}
+
+// applyBig applies the operator to value in place
+func applyBig(value, operand *big.Int, operator models.Operator) error {
+	switch operator {
+	case models.OperatorPlus:
+		value.Add(value, operand)
+	case models.OperatorMinus:
+		value.Sub(value, operand)
+	case models.OperatorMultiply:
+		value.Mul(value, operand)
+	case models.OperatorDivide:
+		if operand.Sign() == 0 {
+			return ErrDivisionByZero
+		}
+		value.Quo(value, operand)
+	default:
+		return ErrInvalidOperator
+	}
+	return nil
+}
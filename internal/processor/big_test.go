@@ -0,0 +1,118 @@
+package processor
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestProcessTaskBig(t *testing.T) {
+	tests := []struct {
+		name    string
+		task    models.Task
+		want    string
+		wantErr error
+	}{
+		{
+			name: "matches native arithmetic",
+			task: models.Task{
+				Value: 10,
+				Operations: []models.Operation{
+					{Operator: models.OperatorPlus, Value: 5},
+					{Operator: models.OperatorMultiply, Value: 2},
+					{Operator: models.OperatorMinus, Value: 3},
+					{Operator: models.OperatorDivide, Value: 4},
+				},
+			},
+			want: "6", // ((10 + 5) * 2 - 3) / 4
+		},
+		{
+			name: "truncates towards zero",
+			task: models.Task{
+				Value:      -7,
+				Operations: []models.Operation{{Operator: models.OperatorDivide, Value: 2}},
+			},
+			want: "-3",
+		},
+		{
+			name: "does not overflow",
+			task: models.Task{
+				Value: math.MaxInt,
+				Operations: []models.Operation{
+					{Operator: models.OperatorMultiply, Value: math.MaxInt},
+					{Operator: models.OperatorPlus, Value: 1},
+				},
+			},
+			want: "85070591730234615847396907784232501250",
+		},
+		{
+			name: "division by zero",
+			task: models.Task{
+				Value:      1,
+				Operations: []models.Operation{{Operator: models.OperatorDivide, Value: 0}},
+			},
+			wantErr: ErrDivisionByZero,
+		},
+		{
+			name: "invalid operator",
+			task: models.Task{
+				Value:      1,
+				Operations: []models.Operation{{Operator: models.OperatorTotalAmount, Value: 1}},
+			},
+			wantErr: ErrInvalidOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ProcessTaskBig(tt.task)
+			if tt.wantErr != nil {
+				if !errors.Is(result.Error, tt.wantErr) {
+					t.Errorf("Expected error %v, got %v", tt.wantErr, result.Error)
+				}
+				return
+			}
+			if result.Error != nil {
+				t.Fatalf("ProcessTaskBig() unexpected error: %v", result.Error)
+			}
+			if got := result.Exact(); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+			if native := ProcessTask(tt.task); result.Big.IsInt64() && native.Result != result.Result {
+				t.Errorf("Expected native result %d to match %d", native.Result, result.Result)
+			}
+		})
+	}
+}
+
+func benchmarkTask() models.Task {
+	return models.Task{
+		Value: 1000,
+		Operations: []models.Operation{
+			{Operator: models.OperatorPlus, Value: 17},
+			{Operator: models.OperatorMultiply, Value: 3},
+			{Operator: models.OperatorMinus, Value: 42},
+			{Operator: models.OperatorDivide, Value: 7},
+			{Operator: models.OperatorMultiply, Value: 11},
+			{Operator: models.OperatorPlus, Value: 5},
+		},
+	}
+}
+
+func BenchmarkProcessTask(b *testing.B) {
+	task := benchmarkTask()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessTask(task)
+	}
+}
+
+func BenchmarkProcessTaskBig(b *testing.B) {
+	task := benchmarkTask()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessTaskBig(task)
+	}
+}
@@ -0,0 +1,107 @@
+package processor
+
+import (
+	"cmp"
+	"math/big"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// MaxNestingDepth is the number of if steps an operation may be nested in
+const MaxNestingDepth = 8
+
+// number is the arithmetic of a mode, as needed to evaluate control steps
+type number[T any] interface {
+	// apply applies an arithmetic operation to value
+	apply(value T, op models.Operation) (T, error)
+	// compare returns -1, 0 or +1 as value is less than, equal to or
+	// greater than x
+	compare(value T, x int) int
+	// fromInt converts x to the value type
+	fromInt(x int) T
+}
+
+// control evaluates the control step op for value. exit reports that an exit
+// step ended the evaluation of the task.
+func control[T any, N number[T]](n N, value T, op models.Operation) (result T, exit bool, err error) {
+	c := op.Control
+	switch {
+	case c.If != nil:
+		branch := c.If.Else
+		if c.If.Compare.Holds(n.compare(value, c.If.Value)) {
+			branch = c.If.Then
+		}
+		return evaluate(n, value, branch)
+	case c.Clamp != nil:
+		if c.Clamp.Min != nil && n.compare(value, *c.Clamp.Min) < 0 {
+			return n.fromInt(*c.Clamp.Min), false, nil
+		}
+		if c.Clamp.Max != nil && n.compare(value, *c.Clamp.Max) > 0 {
+			return n.fromInt(*c.Clamp.Max), false, nil
+		}
+		return value, false, nil
+	case c.Exit != nil:
+		return value, c.Exit.Compare.Holds(n.compare(value, c.Exit.Value)), nil
+	default:
+		return value, false, nil
+	}
+}
+
+// evaluate applies ops to value, stopping at an exit step whose condition holds
+func evaluate[T any, N number[T]](n N, value T, ops []models.Operation) (result T, exit bool, err error) {
+	for _, op := range ops {
+		if op.IsControl() {
+			value, exit, err = control(n, value, op)
+		} else {
+			value, err = n.apply(value, op)
+		}
+		if err != nil || exit {
+			return value, exit, err
+		}
+	}
+	return value, false, nil
+}
+
+type intNumber struct{}
+
+func (intNumber) apply(value int, op models.Operation) (int, error) {
+	return applyOperation(value, op.Operator, op.Value)
+}
+func (intNumber) compare(value, x int) int { return cmp.Compare(value, x) }
+func (intNumber) fromInt(x int) int        { return x }
+
+// bigNumber applies operations in place
+type bigNumber struct{}
+
+func (bigNumber) apply(value *big.Int, op models.Operation) (*big.Int, error) {
+	return value, applyBig(value, big.NewInt(int64(op.Value)), op.Operator)
+}
+func (bigNumber) compare(value *big.Int, x int) int { return value.Cmp(big.NewInt(int64(x))) }
+func (bigNumber) fromInt(x int) *big.Int            { return big.NewInt(int64(x)) }
+
+// decimalNumber works on unscaled values, applying operations in place
+type decimalNumber struct {
+	opts DecimalOptions
+	unit *big.Int
+}
+
+func (d decimalNumber) apply(value *big.Int, op models.Operation) (*big.Int, error) {
+	return value, d.opts.apply(value, big.NewInt(int64(op.Value)), d.unit, op.Operator)
+}
+func (d decimalNumber) compare(value *big.Int, x int) int { return value.Cmp(d.fromInt(x)) }
+func (d decimalNumber) fromInt(x int) *big.Int {
+	return new(big.Int).Mul(big.NewInt(int64(x)), d.unit)
+}
+
+// floatNumber fails operations whose result is not finite
+type floatNumber struct{}
+
+func (floatNumber) apply(value float64, op models.Operation) (float64, error) {
+	result, err := applyFloat(value, op)
+	if err == nil {
+		err = checkFinite(result)
+	}
+	return result, err
+}
+func (floatNumber) compare(value float64, x int) int { return cmp.Compare(value, float64(x)) }
+func (floatNumber) fromInt(x int) float64            { return float64(x) }
@@ -0,0 +1,139 @@
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestControlSteps(t *testing.T) {
+	op := func(operator models.Operator, value int) models.Operation {
+		return models.Operation{Operator: operator, Value: value}
+	}
+	ifStep := func(compare models.Comparison, value int, then, els []models.Operation) models.Operation {
+		return models.Operation{Control: &models.Control{If: &models.If{
+			Condition: models.Condition{Compare: compare, Value: value}, Then: then, Else: els,
+		}}}
+	}
+	clamp := func(lo, hi int) models.Operation {
+		return models.Operation{Control: &models.Control{Clamp: &models.Clamp{Min: &lo, Max: &hi}}}
+	}
+	exit := func(compare models.Comparison, value int) models.Operation {
+		return models.Operation{Control: &models.Control{Exit: &models.Condition{Compare: compare, Value: value}}}
+	}
+
+	// Tiered pricing: 10% off above 100, plus a flat fee of 5 below 20
+	pricing := []models.Operation{
+		ifStep(models.CompareGreater, 100,
+			[]models.Operation{op(models.OperatorMultiply, 9), op(models.OperatorDivide, 10)},
+			[]models.Operation{ifStep(models.CompareLess, 20, []models.Operation{op(models.OperatorPlus, 5)}, nil)},
+		),
+		clamp(0, 500),
+	}
+
+	tests := []struct {
+		name      string
+		task      models.Task
+		want      int
+		wantErr   error
+		wantIndex int
+	}{
+		{name: "then branch", task: models.Task{Value: 200, Operations: pricing}, want: 180},
+		{name: "nested branch", task: models.Task{Value: 10, Operations: pricing}, want: 15},
+		{name: "no branch applies", task: models.Task{Value: 50, Operations: pricing}, want: 50},
+		{name: "clamped", task: models.Task{Value: 1000, Operations: pricing}, want: 500},
+		{
+			name: "exit skips the remaining operations",
+			task: models.Task{Value: 7, Operations: []models.Operation{
+				op(models.OperatorPlus, 3),
+				exit(models.CompareGreaterOrEqual, 10),
+				op(models.OperatorMultiply, 100),
+			}},
+			want: 10,
+		},
+		{
+			name: "exit inside a branch ends the task",
+			task: models.Task{Value: 1, Operations: []models.Operation{
+				ifStep(models.CompareEqual, 1, []models.Operation{op(models.OperatorPlus, 1), exit(models.CompareNotEqual, 0)}, nil),
+				op(models.OperatorMultiply, 100),
+			}},
+			want: 2,
+		},
+		{
+			name: "exit whose condition fails",
+			task: models.Task{Value: 1, Operations: []models.Operation{exit(models.CompareLess, 0), op(models.OperatorPlus, 1)}},
+			want: 2,
+		},
+		{
+			name: "nested failure reports the top-level index",
+			task: models.Task{Value: 1, Operations: []models.Operation{
+				op(models.OperatorPlus, 1),
+				ifStep(models.CompareGreater, 0, []models.Operation{op(models.OperatorDivide, 0)}, nil),
+			}},
+			wantErr:   ErrDivisionByZero,
+			wantIndex: 1,
+		},
+	}
+
+	modes := []struct {
+		name    string
+		process func(models.Task) models.Result
+	}{
+		{name: "int", process: ProcessTask},
+		{name: "big", process: ProcessTaskBig},
+		{name: "decimal", process: DecimalOptions{Scale: 2}.ProcessTask},
+		{name: "float", process: ProcessTaskFloat},
+		{name: "program", process: func(task models.Task) models.Result { return Compile(task.Operations).ProcessTask(task) }},
+		{name: "explain", process: Explain},
+	}
+
+	for _, mode := range modes {
+		for _, tt := range tests {
+			t.Run(mode.name+"/"+tt.name, func(t *testing.T) {
+				result := mode.process(tt.task)
+				if tt.wantErr != nil {
+					if !errors.Is(result.Error, tt.wantErr) {
+						t.Fatalf("Expected error %v, got %v", tt.wantErr, result.Error)
+					}
+					var perr *models.Error
+					if !errors.As(result.Error, &perr) || perr.Index != tt.wantIndex {
+						t.Errorf("Expected index %d, got %v", tt.wantIndex, result.Error)
+					}
+					return
+				}
+				if result.Error != nil {
+					t.Fatalf("Unexpected error: %v", result.Error)
+				}
+				if result.Result != tt.want {
+					t.Errorf("Expected %d, got %d", tt.want, result.Result)
+				}
+			})
+		}
+	}
+}
+
+func TestExplainControlSteps(t *testing.T) {
+	lo, hi := 0, 20
+	task := models.Task{Value: 150, Operations: []models.Operation{
+		{Control: &models.Control{If: &models.If{
+			Condition: models.Condition{Compare: models.CompareGreater, Value: 100},
+			Then:      []models.Operation{{Operator: models.OperatorDivide, Value: 10}},
+		}}},
+		{Control: &models.Control{Clamp: &models.Clamp{Min: &lo, Max: &hi}}},
+		{Control: &models.Control{Exit: &models.Condition{Compare: models.CompareGreater, Value: 10}}},
+		{Operator: models.OperatorPlus, Value: 1},
+	}}
+
+	result := Explain(task)
+	if result.Error != nil {
+		t.Fatalf("Unexpected error: %v", result.Error)
+	}
+	want := "150\nif value > 100: then\n  / 10 = 15\nclamp [0, 20] = 15\nexit since value > 10"
+	if got := result.Explanation.String(); got != want {
+		t.Errorf("Expected explanation\n%s\ngot\n%s", want, got)
+	}
+	if got := result.Explanation.Steps[1].Index; got != 0 {
+		t.Errorf("Expected the nested step to belong to operation 0, got %d", got)
+	}
+}
@@ -0,0 +1,146 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// MaxDecimalScale is the largest number of fractional digits DecimalOptions accepts
+const MaxDecimalScale = 38
+
+var (
+	// ErrInvalidScale is returned when the decimal scale is out of range
+	ErrInvalidScale = fmt.Errorf("decimal scale must be between 0 and %d", MaxDecimalScale)
+	// ErrUnknownRounding is returned by ParseRounding for an unrecognised name
+	ErrUnknownRounding = errors.New("unknown rounding mode")
+)
+
+// Rounding selects how decimal division results are rounded to the scale
+type Rounding int
+
+const (
+	// RoundHalfEven rounds to the nearest value, ties to the even neighbour
+	RoundHalfEven Rounding = iota
+	// RoundHalfUp rounds to the nearest value, ties away from zero
+	RoundHalfUp
+	// RoundTruncate rounds towards zero
+	RoundTruncate
+	RoundingTotalAmount
+)
+
+func (r Rounding) String() string {
+	switch r {
+	case RoundHalfEven:
+		return "half_even"
+	case RoundHalfUp:
+		return "half_up"
+	case RoundTruncate:
+		return "truncate"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseRounding returns the rounding mode named s, as returned by Rounding.String
+func ParseRounding(s string) (Rounding, error) {
+	for r := RoundHalfEven; r < RoundingTotalAmount; r++ {
+		if r.String() == s {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownRounding, s)
+}
+
+// DecimalOptions configures decimal mode
+type DecimalOptions struct {
+	// Scale is the number of fractional digits kept
+	Scale int
+	// Rounding applies when a division has more fractional digits than Scale
+	Rounding Rounding
+}
+
+// Validate checks if the decimal options are valid
+func (o DecimalOptions) Validate() error {
+	if o.Scale < 0 || o.Scale > MaxDecimalScale {
+		return ErrInvalidScale
+	}
+	if o.Rounding < 0 || o.Rounding >= RoundingTotalAmount {
+		return fmt.Errorf("%w: %d", ErrUnknownRounding, int(o.Rounding))
+	}
+	return nil
+}
+
+// ProcessTask processes a task like ProcessTask using fixed-point decimals
+// with o.Scale fractional digits. Only division can produce digits beyond the
+// scale; its quotient is rounded with o.Rounding.
+func (o DecimalOptions) ProcessTask(task models.Task) models.Result {
+	unit := models.Pow10(o.Scale)
+	value := new(big.Int).Mul(big.NewInt(int64(task.Value)), unit)
+
+	operand := new(big.Int)
+	for i, op := range task.Operations {
+		var err error
+		exit := false
+		if op.IsControl() {
+			value, exit, err = control(decimalNumber{opts: o, unit: unit}, value, op)
+		} else {
+			operand.SetInt64(int64(op.Value))
+			err = o.apply(value, operand, unit, op.Operator)
+		}
+		if err != nil {
+			return models.Result{Error: models.WrapError(err, models.StageProcessor, task.ID, i)}
+		}
+		if exit {
+			break
+		}
+	}
+
+	return models.DecimalResult(models.Decimal{Unscaled: value, Scale: o.Scale})
+}
+
+// apply applies the operator to the unscaled value in place
+func (o DecimalOptions) apply(value, operand, unit *big.Int, operator models.Operator) error {
+	switch operator {
+	case models.OperatorPlus:
+		value.Add(value, new(big.Int).Mul(operand, unit))
+	case models.OperatorMinus:
+		value.Sub(value, new(big.Int).Mul(operand, unit))
+	case models.OperatorMultiply:
+		value.Mul(value, operand)
+	case models.OperatorDivide:
+		if operand.Sign() == 0 {
+			return ErrDivisionByZero
+		}
+		o.Rounding.quo(value, operand)
+	default:
+		return ErrInvalidOperator
+	}
+	return nil
+}
+
+// quo sets x to x / y rounded to an integer with the rounding mode
+func (r Rounding) quo(x, y *big.Int) {
+	rem := new(big.Int)
+	x.QuoRem(x, y, rem)
+	if rem.Sign() == 0 || r == RoundTruncate {
+		return
+	}
+
+	// Compare twice the remainder with the divisor to find the nearest value
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	cmp := twice.CmpAbs(y)
+	if cmp < 0 || (cmp == 0 && r == RoundHalfEven && x.Bit(0) == 0) {
+		return
+	}
+
+	// The exact quotient is negative when the remainder and divisor differ in sign
+	if rem.Sign() == y.Sign() {
+		x.Add(x, big.NewInt(1))
+	} else {
+		x.Sub(x, big.NewInt(1))
+	}
+}
@@ -0,0 +1,100 @@
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestDecimalProcessTask(t *testing.T) {
+	div := func(value, divisor int) models.Task {
+		return models.Task{Value: value, Operations: []models.Operation{{Operator: models.OperatorDivide, Value: divisor}}}
+	}
+
+	tests := []struct {
+		name    string
+		opts    DecimalOptions
+		task    models.Task
+		want    string
+		wantErr error
+	}{
+		{
+			name: "keeps fractional digits",
+			opts: DecimalOptions{Scale: 2},
+			task: models.Task{
+				Value: 10,
+				Operations: []models.Operation{
+					{Operator: models.OperatorDivide, Value: 4},
+					{Operator: models.OperatorPlus, Value: 1},
+					{Operator: models.OperatorMultiply, Value: 3},
+					{Operator: models.OperatorMinus, Value: 2},
+				},
+			},
+			want: "8.50", // (10 / 4 + 1) * 3 - 2
+		},
+		{name: "half even rounds ties to even", opts: DecimalOptions{Scale: 1, Rounding: RoundHalfEven}, task: div(1, 4), want: "0.2"},
+		{name: "half even rounds ties up to even", opts: DecimalOptions{Scale: 1, Rounding: RoundHalfEven}, task: div(3, 4), want: "0.8"},
+		{name: "half even rounds negative ties", opts: DecimalOptions{Scale: 1, Rounding: RoundHalfEven}, task: div(-1, 4), want: "-0.2"},
+		{name: "half up rounds ties away from zero", opts: DecimalOptions{Scale: 1, Rounding: RoundHalfUp}, task: div(1, 4), want: "0.3"},
+		{name: "half up rounds negative ties away from zero", opts: DecimalOptions{Scale: 1, Rounding: RoundHalfUp}, task: div(1, -4), want: "-0.3"},
+		{name: "half up rounds to nearest", opts: DecimalOptions{Scale: 2, Rounding: RoundHalfUp}, task: div(2, 3), want: "0.67"},
+		{name: "truncate rounds towards zero", opts: DecimalOptions{Scale: 2, Rounding: RoundTruncate}, task: div(-2, 3), want: "-0.66"},
+		{name: "zero scale", opts: DecimalOptions{Scale: 0, Rounding: RoundHalfEven}, task: div(5, 2), want: "2"},
+		{name: "division by zero", opts: DecimalOptions{Scale: 2}, task: div(1, 0), wantErr: ErrDivisionByZero},
+		{
+			name:    "invalid operator",
+			opts:    DecimalOptions{Scale: 2},
+			task:    models.Task{Value: 1, Operations: []models.Operation{{Operator: models.OperatorTotalAmount}}},
+			wantErr: ErrInvalidOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.opts.ProcessTask(tt.task)
+			if tt.wantErr != nil {
+				if !errors.Is(result.Error, tt.wantErr) {
+					t.Errorf("Expected error %v, got %v", tt.wantErr, result.Error)
+				}
+				return
+			}
+			if result.Error != nil {
+				t.Fatalf("ProcessTask() unexpected error: %v", result.Error)
+			}
+			if got := result.Exact(); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDecimalOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    DecimalOptions
+		wantErr error
+	}{
+		{name: "valid", opts: DecimalOptions{Scale: 4, Rounding: RoundHalfUp}},
+		{name: "negative scale", opts: DecimalOptions{Scale: -1}, wantErr: ErrInvalidScale},
+		{name: "scale too large", opts: DecimalOptions{Scale: MaxDecimalScale + 1}, wantErr: ErrInvalidScale},
+		{name: "unknown rounding", opts: DecimalOptions{Rounding: RoundingTotalAmount}, wantErr: ErrUnknownRounding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	for r := RoundHalfEven; r < RoundingTotalAmount; r++ {
+		if got, err := ParseRounding(r.String()); err != nil || got != r {
+			t.Errorf("ParseRounding(%q) = %v, %v", r.String(), got, err)
+		}
+	}
+	if _, err := ParseRounding("ceiling"); !errors.Is(err, ErrUnknownRounding) {
+		t.Errorf("Expected ErrUnknownRounding, got %v", err)
+	}
+}
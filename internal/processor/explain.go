@@ -0,0 +1,100 @@
+package processor
+
+import (
+	"cmp"
+	"fmt"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// Explain processes a task like ProcessTask and sets the result's
+// Explanation to the value produced by every operation, including those of
+// the if branches taken. When an operation fails, the explanation shows the
+// operations applied before it.
+func Explain(task models.Task) models.Result {
+	explanation := &models.Explanation{Value: task.Value, Steps: make([]models.ExplainStep, 0, len(task.Operations))}
+	result := task.Value
+
+	for i, op := range task.Operations {
+		var err error
+		exit := false
+		result, exit, err = explainOperation(explanation, i, 0, result, op)
+		if err != nil {
+			return models.Result{
+				Error:       models.WrapError(err, models.StageProcessor, task.ID, i),
+				Explanation: explanation,
+			}
+		}
+		if exit {
+			break
+		}
+	}
+
+	return models.Result{Result: result, Explanation: explanation}
+}
+
+// explainOperation applies op to value like control and applyOperation,
+// recording its steps under the top-level index i at the given depth
+func explainOperation(e *models.Explanation, i, depth, value int, op models.Operation) (int, bool, error) {
+	record := func(step models.ExplainStep) {
+		step.Index, step.Depth = i, depth
+		e.Steps = append(e.Steps, step)
+	}
+
+	c := op.Control
+	switch {
+	case c == nil:
+		result, err := applyOperation(value, op.Operator, op.Value)
+		if err != nil {
+			return value, false, err
+		}
+		record(models.ExplainStep{Operator: op.Operator, Operand: op.Value, Result: result})
+		return result, false, nil
+	case c.If != nil:
+		branch, name := c.If.Else, "else"
+		if c.If.Compare.Holds(cmp.Compare(value, c.If.Value)) {
+			branch, name = c.If.Then, "then"
+		}
+		record(models.ExplainStep{Control: fmt.Sprintf("if %s: %s", c.If.Condition, name), Result: value})
+		for _, nested := range branch {
+			var err error
+			exit := false
+			if value, exit, err = explainOperation(e, i, depth+1, value, nested); err != nil || exit {
+				return value, exit, err
+			}
+		}
+		return value, false, nil
+	default:
+		result, exit, err := control(intNumber{}, value, op)
+		if c.Clamp != nil {
+			record(models.ExplainStep{Control: fmt.Sprintf("%s = %d", c.Clamp, result), Result: result})
+		} else if exit {
+			record(models.ExplainStep{Control: fmt.Sprintf("exit since %s", c.Exit), Result: result})
+		}
+		return result, exit, err
+	}
+}
+
+// ProcessTaskExplainErrors processes a task like ProcessTask and, only when
+// it fails, evaluates it again with Explain so the error result shows where
+// evaluation stopped. Successful tasks cost nothing extra.
+func ProcessTaskExplainErrors(task models.Task) models.Result {
+	result := ProcessTask(task)
+	if result.Error != nil {
+		return Explain(task)
+	}
+	return result
+}
+
+// ExplainErrors returns a function processing tasks with process, which must
+// give the same results as ProcessTask, and explaining failed tasks like
+// ProcessTaskExplainErrors
+func ExplainErrors(process func(models.Task) models.Result) func(models.Task) models.Result {
+	return func(task models.Task) models.Result {
+		result := process(task)
+		if result.Error != nil {
+			return Explain(task)
+		}
+		return result
+	}
+}
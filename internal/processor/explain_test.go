@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name      string
+		task      models.Task
+		wantSteps []int
+		want      int
+		wantErr   error
+	}{
+		{
+			name: "records every intermediate value",
+			task: models.Task{
+				Value: 10,
+				Operations: []models.Operation{
+					{Operator: models.OperatorPlus, Value: 5},
+					{Operator: models.OperatorMultiply, Value: 2},
+					{Operator: models.OperatorDivide, Value: 4},
+				},
+			},
+			wantSteps: []int{15, 30, 7},
+			want:      7,
+		},
+		{
+			name:      "no operations",
+			task:      models.Task{Value: 3, Operations: []models.Operation{}},
+			wantSteps: []int{},
+			want:      3,
+		},
+		{
+			name: "stops before the failing operation",
+			task: models.Task{
+				Value: 7,
+				Operations: []models.Operation{
+					{Operator: models.OperatorMinus, Value: 2},
+					{Operator: models.OperatorDivide, Value: 0},
+					{Operator: models.OperatorPlus, Value: 1},
+				},
+			},
+			wantSteps: []int{5},
+			wantErr:   ErrDivisionByZero,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Explain(tt.task)
+			if !errors.Is(result.Error, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, result.Error)
+			}
+			if result.Explanation == nil {
+				t.Fatal("Expected an explanation")
+			}
+			if result.Explanation.Value != tt.task.Value {
+				t.Errorf("Expected initial value %d, got %d", tt.task.Value, result.Explanation.Value)
+			}
+			if len(result.Explanation.Steps) != len(tt.wantSteps) {
+				t.Fatalf("Expected %d steps, got %+v", len(tt.wantSteps), result.Explanation.Steps)
+			}
+			for i, step := range result.Explanation.Steps {
+				op := tt.task.Operations[i]
+				if step.Index != i || step.Operator != op.Operator || step.Operand != op.Value || step.Result != tt.wantSteps[i] {
+					t.Errorf("Expected step %d to be %v %d = %d, got %+v", i, op.Operator, op.Value, tt.wantSteps[i], step)
+				}
+			}
+			if tt.wantErr == nil && result.Result != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, result.Result)
+			}
+		})
+	}
+}
+
+func TestProcessTaskExplainErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		process func(models.Task) models.Result
+	}{
+		{name: "ProcessTaskExplainErrors", process: ProcessTaskExplainErrors},
+		{name: "ExplainErrors with a program cache", process: ExplainErrors(NewProgramCache(4).ProcessTask)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok := models.Task{Value: 1, Operations: []models.Operation{{Operator: models.OperatorPlus, Value: 1}}}
+			if result := tt.process(ok); result.Result != 2 || result.Explanation != nil {
+				t.Errorf("Expected 2 without explanation, got %+v", result)
+			}
+
+			failing := models.Task{Value: 1, Operations: []models.Operation{{Operator: models.OperatorDivide, Value: 0}}}
+			result := tt.process(failing)
+			if !errors.Is(result.Error, ErrDivisionByZero) || result.Explanation == nil {
+				t.Errorf("Expected an explained division by zero, got %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package processor
+
+import (
+	"math"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// ProcessTaskFloat processes a task like ProcessTask using float64
+// arithmetic, so division yields fractional results. Task and operation
+// values are taken from their Float fields when set. An operation producing
+// NaN or an infinity fails with ErrNaN or ErrInfinity.
+func ProcessTaskFloat(task models.Task) models.Result {
+	result := task.FloatValue()
+	if err := checkFinite(result); err != nil {
+		return models.Result{Error: models.WrapError(err, models.StageProcessor, task.ID, -1)}
+	}
+
+	for i, op := range task.Operations {
+		var err error
+		exit := false
+		if op.IsControl() {
+			result, exit, err = control(floatNumber{}, result, op)
+		} else {
+			result, err = floatNumber{}.apply(result, op)
+		}
+		if err != nil {
+			return models.Result{Error: models.WrapError(err, models.StageProcessor, task.ID, i)}
+		}
+		if exit {
+			break
+		}
+	}
+
+	return models.FloatResult(result)
+}
+
+func applyFloat(value float64, op models.Operation) (float64, error) {
+	operand := op.FloatValue()
+	switch op.Operator {
+	case models.OperatorPlus:
+		return value + operand, nil
+	case models.OperatorMinus:
+		return value - operand, nil
+	case models.OperatorMultiply:
+		return value * operand, nil
+	case models.OperatorDivide:
+		if operand == 0 {
+			return 0, ErrDivisionByZero
+		}
+		return value / operand, nil
+	default:
+		return 0, ErrInvalidOperator
+	}
+}
+
+// checkFinite returns ErrNaN or ErrInfinity if v is not a finite number
+func checkFinite(v float64) error {
+	switch {
+	case math.IsNaN(v):
+		return ErrNaN
+	case math.IsInf(v, 0):
+		return ErrInfinity
+	default:
+		return nil
+	}
+}
+
+// kahanSum adds up values with compensated (Kahan-Babuska) summation, so the
+// rounding error does not grow with the number of values
+func kahanSum(values []float64) float64 {
+	var sum, c float64
+	for _, v := range values {
+		t := sum + v
+		if math.Abs(sum) >= math.Abs(v) {
+			c += (sum - t) + v
+		} else {
+			c += (v - t) + sum
+		}
+		sum = t
+	}
+	if math.IsInf(sum, 0) {
+		// The compensation of an overflowed sum is meaningless
+		return sum
+	}
+	return sum + c
+}
@@ -0,0 +1,109 @@
+package processor
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestProcessTaskFloat(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		task    models.Task
+		want    string
+		wantErr error
+	}{
+		{
+			name: "keeps fractional results",
+			task: models.Task{
+				Value: 10,
+				Operations: []models.Operation{
+					{Operator: models.OperatorDivide, Value: 4},
+					{Operator: models.OperatorMultiply, Value: 3},
+				},
+			},
+			want: "7.5",
+		},
+		{
+			name: "float fields replace values",
+			task: models.Task{
+				Value:      100,
+				Float:      f(0.5),
+				Operations: []models.Operation{{Operator: models.OperatorPlus, Value: 100, Float: f(0.25)}},
+			},
+			want: "0.75",
+		},
+		{
+			name:    "division by zero",
+			task:    models.Task{Value: 1, Operations: []models.Operation{{Operator: models.OperatorDivide, Float: f(0)}}},
+			wantErr: ErrDivisionByZero,
+		},
+		{
+			name:    "overflow to infinity",
+			task:    models.Task{Float: f(math.MaxFloat64), Operations: []models.Operation{{Operator: models.OperatorMultiply, Value: 10}}},
+			wantErr: ErrInfinity,
+		},
+		{
+			name:    "not a number",
+			task:    models.Task{Value: 1, Operations: []models.Operation{{Operator: models.OperatorPlus, Float: f(math.NaN())}}},
+			wantErr: ErrNaN,
+		},
+		{
+			name:    "infinite initial value",
+			task:    models.Task{Float: f(math.Inf(-1)), Operations: []models.Operation{}},
+			wantErr: ErrInfinity,
+		},
+		{
+			name:    "invalid operator",
+			task:    models.Task{Value: 1, Operations: []models.Operation{{Operator: models.OperatorTotalAmount}}},
+			wantErr: ErrInvalidOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ProcessTaskFloat(tt.task)
+			if tt.wantErr != nil {
+				if !errors.Is(result.Error, tt.wantErr) {
+					t.Errorf("Expected error %v, got %v", tt.wantErr, result.Error)
+				}
+				return
+			}
+			if result.Error != nil {
+				t.Fatalf("ProcessTaskFloat() unexpected error: %v", result.Error)
+			}
+			if got := result.Exact(); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestKahanSum(t *testing.T) {
+	tenths := make([]float64, 10)
+	for i := range tenths {
+		tenths[i] = 0.1
+	}
+
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{name: "empty", values: nil, want: 0},
+		{name: "repeated tenths", values: tenths, want: 1},
+		{name: "large cancelling terms", values: []float64{1e16, 1, -1e16}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kahanSum(tt.values); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package processor
+
+import (
+	"math"
+	"math/big"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// Overflow classifies how an operation relates to the int range
+type Overflow int
+
+const (
+	// OverflowNone means every possible result fits in an int
+	OverflowNone Overflow = iota
+	// OverflowPossible means some, but not all, possible results overflow
+	OverflowPossible
+	// OverflowCertain means every possible result overflows
+	OverflowCertain
+)
+
+func (o Overflow) String() string {
+	switch o {
+	case OverflowNone:
+		return "none"
+	case OverflowPossible:
+		return "possible"
+	case OverflowCertain:
+		return "certain"
+	default:
+		return "unknown"
+	}
+}
+
+// Interval is an inclusive range of int values
+type Interval struct {
+	Min int
+	Max int
+}
+
+// Point returns the interval holding only v
+func Point(v int) Interval {
+	return Interval{Min: v, Max: v}
+}
+
+// RangeAnalysis is the outcome of AnalyzeRange
+type RangeAnalysis struct {
+	// Steps holds the interval of the value after each analysed operation
+	Steps []Interval
+	// Index is the position of the first operation that may overflow, or -1
+	Index int
+	// Overflow tells whether the operation at Index always or only possibly
+	// overflows for the values reaching it
+	Overflow Overflow
+}
+
+// bounds is an interval with exact bounds; a nil *bounds holds no values
+type bounds struct {
+	lo, hi *big.Int
+}
+
+var (
+	minInt = big.NewInt(math.MinInt)
+	maxInt = big.NewInt(math.MaxInt)
+)
+
+// AnalyzeRange computes the interval of every intermediate value of ops for
+// an initial value within start, using exact interval arithmetic. An if step
+// takes the union of the branches reachable from the interval, and an exit
+// step keeps the values that go on. It stops at the first operation that may
+// overflow, where every value has exited, and at an invalid operation or a
+// zero divisor, which ValidateTask reports on its own.
+func AnalyzeRange(start Interval, ops []models.Operation) RangeAnalysis {
+	analysis := RangeAnalysis{Index: -1}
+	if start.Min > start.Max {
+		start.Min, start.Max = start.Max, start.Min
+	}
+
+	b := &bounds{lo: big.NewInt(int64(start.Min)), hi: big.NewInt(int64(start.Max))}
+	for i, op := range ops {
+		next, overflow, ok := analyzeOperation(b, op)
+		if overflow != OverflowNone {
+			analysis.Index = i
+			analysis.Overflow = overflow
+			break
+		}
+		if !ok || next == nil {
+			break
+		}
+		b = next
+		analysis.Steps = append(analysis.Steps, Interval{Min: int(b.lo.Int64()), Max: int(b.hi.Int64())})
+	}
+	return analysis
+}
+
+// analyzeOperation returns the bounds of the values after op for values
+// within b, nil if every value exits, and how op may overflow. It returns
+// false if op cannot be analysed.
+func analyzeOperation(b *bounds, op models.Operation) (*bounds, Overflow, bool) {
+	c := op.Control
+	switch {
+	case c == nil:
+		lo, hi, ok := applyInterval(b.lo, b.hi, op)
+		if !ok {
+			return nil, OverflowNone, false
+		}
+		if overflow := classify(lo, hi); overflow != OverflowNone {
+			return nil, overflow, true
+		}
+		return &bounds{lo: lo, hi: hi}, OverflowNone, true
+	case c.If != nil:
+		if !validComparison(c.If.Compare) {
+			return nil, OverflowNone, false
+		}
+		negated := models.Condition{Compare: c.If.Compare.Negate(), Value: c.If.Value}
+		branches := []struct {
+			in  *bounds
+			ops []models.Operation
+		}{
+			{in: restrict(b, c.If.Condition), ops: c.If.Then},
+			{in: restrict(b, negated), ops: c.If.Else},
+		}
+
+		var out *bounds
+		reachable, certain, possible := 0, 0, 0
+		for _, branch := range branches {
+			if branch.in == nil {
+				continue
+			}
+			reachable++
+			result, overflow, ok := analyzeOperations(branch.in, branch.ops)
+			if !ok {
+				return nil, OverflowNone, false
+			}
+			switch overflow {
+			case OverflowCertain:
+				certain++
+			case OverflowPossible:
+				possible++
+			}
+			out = union(out, result)
+		}
+		switch {
+		case certain == reachable && certain > 0:
+			return nil, OverflowCertain, true
+		case certain+possible > 0:
+			return nil, OverflowPossible, true
+		}
+		return out, OverflowNone, true
+	case c.Clamp != nil:
+		return &bounds{lo: clampBound(b.lo, c.Clamp), hi: clampBound(b.hi, c.Clamp)}, OverflowNone, true
+	case c.Exit != nil:
+		if !validComparison(c.Exit.Compare) {
+			return nil, OverflowNone, false
+		}
+		return restrict(b, models.Condition{Compare: c.Exit.Compare.Negate(), Value: c.Exit.Value}), OverflowNone, true
+	default:
+		return nil, OverflowNone, false
+	}
+}
+
+// analyzeOperations applies analyzeOperation to ops in turn
+func analyzeOperations(b *bounds, ops []models.Operation) (*bounds, Overflow, bool) {
+	for _, op := range ops {
+		if b == nil {
+			break
+		}
+		next, overflow, ok := analyzeOperation(b, op)
+		if overflow != OverflowNone || !ok {
+			return nil, overflow, ok
+		}
+		b = next
+	}
+	return b, OverflowNone, true
+}
+
+// restrict returns the values within b satisfying the condition. For !=,
+// which may split the interval, it only excludes an equal bound.
+func restrict(b *bounds, c models.Condition) *bounds {
+	x := big.NewInt(int64(c.Value))
+	lo, hi := b.lo, b.hi
+	switch c.Compare {
+	case models.CompareGreater:
+		lo = maxBig(lo, new(big.Int).Add(x, big.NewInt(1)))
+	case models.CompareGreaterOrEqual:
+		lo = maxBig(lo, x)
+	case models.CompareLess:
+		hi = minBig(hi, new(big.Int).Sub(x, big.NewInt(1)))
+	case models.CompareLessOrEqual:
+		hi = minBig(hi, x)
+	case models.CompareEqual:
+		lo, hi = maxBig(lo, x), minBig(hi, x)
+	case models.CompareNotEqual:
+		if lo.Cmp(x) == 0 {
+			lo = new(big.Int).Add(x, big.NewInt(1))
+		}
+		if hi.Cmp(x) == 0 {
+			hi = new(big.Int).Sub(x, big.NewInt(1))
+		}
+	}
+	if lo.Cmp(hi) > 0 {
+		return nil
+	}
+	return &bounds{lo: lo, hi: hi}
+}
+
+// union returns the smallest interval holding a and b
+func union(a, b *bounds) *bounds {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	return &bounds{lo: minBig(a.lo, b.lo), hi: maxBig(a.hi, b.hi)}
+}
+
+// clampBound clamps a bound; clamping is monotonic, so the bounds map to the
+// bounds
+func clampBound(v *big.Int, c *models.Clamp) *big.Int {
+	if c.Min != nil && v.Cmp(big.NewInt(int64(*c.Min))) < 0 {
+		return big.NewInt(int64(*c.Min))
+	}
+	if c.Max != nil && v.Cmp(big.NewInt(int64(*c.Max))) > 0 {
+		return big.NewInt(int64(*c.Max))
+	}
+	return v
+}
+
+func minBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// applyInterval returns the bounds of op applied to every value in [lo, hi],
+// or false if the operation cannot be analysed
+func applyInterval(lo, hi *big.Int, op models.Operation) (*big.Int, *big.Int, bool) {
+	v := big.NewInt(int64(op.Value))
+	switch op.Operator {
+	case models.OperatorPlus:
+		return new(big.Int).Add(lo, v), new(big.Int).Add(hi, v), true
+	case models.OperatorMinus:
+		return new(big.Int).Sub(lo, v), new(big.Int).Sub(hi, v), true
+	case models.OperatorMultiply:
+		a, b := new(big.Int).Mul(lo, v), new(big.Int).Mul(hi, v)
+		return order(a, b)
+	case models.OperatorDivide:
+		if op.Value == 0 {
+			return nil, nil, false
+		}
+		// Truncated division by a constant is monotonic, so the bounds map
+		// to the bounds
+		a, b := new(big.Int).Quo(lo, v), new(big.Int).Quo(hi, v)
+		return order(a, b)
+	default:
+		return nil, nil, false
+	}
+}
+
+func order(a, b *big.Int) (*big.Int, *big.Int, bool) {
+	if a.Cmp(b) > 0 {
+		return b, a, true
+	}
+	return a, b, true
+}
+
+// classify reports how much of [lo, hi] lies outside the int range
+func classify(lo, hi *big.Int) Overflow {
+	switch {
+	case hi.Cmp(minInt) < 0 || lo.Cmp(maxInt) > 0:
+		return OverflowCertain
+	case lo.Cmp(minInt) < 0 || hi.Cmp(maxInt) > 0:
+		return OverflowPossible
+	default:
+		return OverflowNone
+	}
+}
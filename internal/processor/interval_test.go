@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestAnalyzeRange(t *testing.T) {
+	op := func(operator models.Operator, value int) models.Operation {
+		return models.Operation{Operator: operator, Value: value}
+	}
+	ifStep := func(compare models.Comparison, value int, then, els []models.Operation) models.Operation {
+		return models.Operation{Control: &models.Control{If: &models.If{
+			Condition: models.Condition{Compare: compare, Value: value}, Then: then, Else: els,
+		}}}
+	}
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name         string
+		start        Interval
+		ops          []models.Operation
+		wantSteps    []Interval
+		wantIndex    int
+		wantOverflow Overflow
+	}{
+		{
+			name:      "exact steps for a single value",
+			start:     Point(10),
+			ops:       []models.Operation{op(models.OperatorPlus, 5), op(models.OperatorMultiply, 2), op(models.OperatorDivide, 4)},
+			wantSteps: []Interval{{15, 15}, {30, 30}, {7, 7}},
+			wantIndex: -1,
+		},
+		{
+			name:      "negative factor swaps bounds",
+			start:     Interval{Min: -2, Max: 3},
+			ops:       []models.Operation{op(models.OperatorMultiply, -4), op(models.OperatorDivide, -3)},
+			wantSteps: []Interval{{-12, 8}, {-2, 4}},
+			wantIndex: -1,
+		},
+		{
+			name:         "certain overflow",
+			start:        Point(math.MaxInt - 1),
+			ops:          []models.Operation{op(models.OperatorMinus, 1), op(models.OperatorPlus, 3)},
+			wantSteps:    []Interval{{math.MaxInt - 2, math.MaxInt - 2}},
+			wantIndex:    1,
+			wantOverflow: OverflowCertain,
+		},
+		{
+			name:         "possible overflow",
+			start:        Interval{Min: 0, Max: math.MaxInt / 2},
+			ops:          []models.Operation{op(models.OperatorMultiply, 3)},
+			wantIndex:    0,
+			wantOverflow: OverflowPossible,
+		},
+		{
+			name:         "dividing the minimum by minus one",
+			start:        Point(math.MinInt),
+			ops:          []models.Operation{op(models.OperatorDivide, -1)},
+			wantIndex:    0,
+			wantOverflow: OverflowCertain,
+		},
+		{
+			name:  "unites the reachable branches",
+			start: Interval{Min: 0, Max: 200},
+			ops: []models.Operation{
+				ifStep(models.CompareGreater, 100, []models.Operation{op(models.OperatorMinus, 100)}, []models.Operation{op(models.OperatorPlus, 1000)}),
+			},
+			wantSteps: []Interval{{1, 1100}}, // [101, 200] - 100 and [0, 100] + 1000
+			wantIndex: -1,
+		},
+		{
+			name:  "follows the only reachable branch",
+			start: Point(150),
+			ops: []models.Operation{
+				ifStep(models.CompareGreater, 100, []models.Operation{op(models.OperatorMinus, 100)}, []models.Operation{op(models.OperatorMultiply, math.MaxInt)}),
+			},
+			wantSteps: []Interval{{50, 50}},
+			wantIndex: -1,
+		},
+		{
+			name:  "possible overflow in one branch",
+			start: Interval{Min: 0, Max: 200},
+			ops: []models.Operation{
+				ifStep(models.CompareLessOrEqual, 100, []models.Operation{op(models.OperatorMultiply, math.MaxInt)}, nil),
+			},
+			wantIndex:    0,
+			wantOverflow: OverflowPossible,
+		},
+		{
+			name:  "clamps the bounds",
+			start: Interval{Min: -50, Max: 50},
+			ops: []models.Operation{
+				{Control: &models.Control{Clamp: &models.Clamp{Min: intPtr(0), Max: intPtr(10)}}},
+			},
+			wantSteps: []Interval{{0, 10}},
+			wantIndex: -1,
+		},
+		{
+			name:  "keeps the values that do not exit",
+			start: Interval{Min: 0, Max: math.MaxInt},
+			ops: []models.Operation{
+				{Control: &models.Control{Exit: &models.Condition{Compare: models.CompareGreater, Value: 10}}},
+				op(models.OperatorMultiply, 1000),
+			},
+			wantSteps: []Interval{{0, 10}, {0, 10000}},
+			wantIndex: -1,
+		},
+		{
+			name:  "stops once every value exits",
+			start: Point(math.MaxInt),
+			ops: []models.Operation{
+				{Control: &models.Control{Exit: &models.Condition{Compare: models.CompareNotEqual, Value: 0}}},
+				op(models.OperatorPlus, 1),
+			},
+			wantIndex: -1,
+		},
+		{
+			name:      "stops at a zero divisor",
+			start:     Point(1),
+			ops:       []models.Operation{op(models.OperatorPlus, 1), op(models.OperatorDivide, 0), op(models.OperatorPlus, 1)},
+			wantSteps: []Interval{{2, 2}},
+			wantIndex: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AnalyzeRange(tt.start, tt.ops)
+			if !reflect.DeepEqual(got.Steps, tt.wantSteps) {
+				t.Errorf("Expected steps %v, got %v", tt.wantSteps, got.Steps)
+			}
+			if got.Index != tt.wantIndex {
+				t.Errorf("Expected index %d, got %d", tt.wantIndex, got.Index)
+			}
+			if got.Overflow != tt.wantOverflow {
+				t.Errorf("Expected overflow %s, got %s", tt.wantOverflow, got.Overflow)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// Mode selects the arithmetic tasks are evaluated with
+type Mode int
+
+const (
+	// ModeInt evaluates tasks with native int arithmetic
+	ModeInt Mode = iota
+	// ModeBig evaluates tasks with arbitrary-precision integers, so results
+	// are exact and never overflow
+	ModeBig
+	// ModeDecimal evaluates tasks with fixed-point decimals, so division
+	// keeps fractional digits
+	ModeDecimal
+	// ModeFloat evaluates tasks with float64 arithmetic, using the Float
+	// values of tasks and operations when set
+	ModeFloat
+	ModeTotalAmount
+)
+
+// ErrUnknownMode is returned by ParseMode for an unrecognised name
+var ErrUnknownMode = errors.New("unknown arithmetic mode")
+
+func (m Mode) String() string {
+	switch m {
+	case ModeInt:
+		return "int"
+	case ModeBig:
+		return "big"
+	case ModeDecimal:
+		return "decimal"
+	case ModeFloat:
+		return "float"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseMode returns the mode named s, as returned by Mode.String
+func ParseMode(s string) (Mode, error) {
+	for m := ModeInt; m < ModeTotalAmount; m++ {
+		if m.String() == s {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownMode, s)
+}
+
+// Validate checks if the mode is known
+func (m Mode) Validate() error {
+	if m < 0 || m >= ModeTotalAmount {
+		return fmt.Errorf("%w: %d", ErrUnknownMode, int(m))
+	}
+	return nil
+}
+
+// ProcessFunc returns the function processing tasks in the mode; dec is only
+// used by ModeDecimal
+func (m Mode) ProcessFunc(dec DecimalOptions) func(models.Task) models.Result {
+	switch m {
+	case ModeBig:
+		return ProcessTaskBig
+	case ModeDecimal:
+		return dec.ProcessTask
+	case ModeFloat:
+		return ProcessTaskFloat
+	default:
+		return ProcessTask
+	}
+}
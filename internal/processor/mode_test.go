@@ -0,0 +1,47 @@
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestMode(t *testing.T) {
+	for m := ModeInt; m < ModeTotalAmount; m++ {
+		t.Run(m.String(), func(t *testing.T) {
+			got, err := ParseMode(m.String())
+			if err != nil {
+				t.Fatalf("ParseMode(%q) error = %v", m.String(), err)
+			}
+			if got != m {
+				t.Errorf("Expected %v, got %v", m, got)
+			}
+			if err := m.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+
+	if _, err := ParseMode("float128"); !errors.Is(err, ErrUnknownMode) {
+		t.Errorf("Expected ErrUnknownMode, got %v", err)
+	}
+	if err := ModeTotalAmount.Validate(); !errors.Is(err, ErrUnknownMode) {
+		t.Errorf("Expected ErrUnknownMode, got %v", err)
+	}
+
+	task := models.Task{Value: 2, Operations: []models.Operation{{Operator: models.OperatorMultiply, Value: 3}}}
+	dec := DecimalOptions{Scale: 2}
+	if got := ModeInt.ProcessFunc(dec)(task); got.Big != nil || got.Result != 6 {
+		t.Errorf("Expected native result 6, got %+v", got)
+	}
+	if got := ModeBig.ProcessFunc(dec)(task); got.Big == nil || got.Exact() != "6" {
+		t.Errorf("Expected exact result 6, got %+v", got)
+	}
+	if got := ModeDecimal.ProcessFunc(dec)(task); got.Decimal == nil || got.Exact() != "6.00" {
+		t.Errorf("Expected decimal result 6.00, got %+v", got)
+	}
+	if got := ModeFloat.ProcessFunc(dec)(task); got.Float == nil || got.Exact() != "6" {
+		t.Errorf("Expected float result 6, got %+v", got)
+	}
+}
@@ -1,46 +1,66 @@
 package processor
 
 import (
+	"math"
+
 	"concurrent-pipeline-processor/pkg/models"
-	"errors"
 )
 
 var (
-	ErrDivisionByZero = errors.New("division by zero")
+	ErrDivisionByZero = models.NewError(models.CodeDivisionByZero, "division by zero")
 )
 
-// ProcessTask processes a single task by applying all operations
+// ProcessTask processes a single task by applying all operations. A failing
+// operation is reported as a *models.Error holding its index; for operations
+// nested in an if step, that is the index of the top-level step.
 func ProcessTask(task models.Task) models.Result {
-	result := task.Value
+	result, index, err := processInt(task.Value, task.Operations)
+	if err != nil {
+		return models.Result{Error: models.WrapError(err, models.StageProcessor, task.ID, index)}
+	}
+	return models.Result{Result: result}
+}
 
-	for _, op := range task.Operations {
+// processInt applies ops to value, returning the index of the failing
+// operation along with its error
+func processInt(value int, ops []models.Operation) (int, int, error) {
+	for i := range ops {
+		// Avoid copying each operation, which is several words long
+		op := &ops[i]
 		var err error
-		result, err = applyOperation(result, op)
+		exit := false
+		if op.IsControl() {
+			value, exit, err = control(intNumber{}, value, *op)
+		} else {
+			value, err = applyOperation(value, op.Operator, op.Value)
+		}
 		if err != nil {
-			return models.Result{Error: err}
+			return 0, i, err
+		}
+		if exit {
+			break
 		}
 	}
-
-	return models.Result{Result: result}
+	return value, 0, nil
 }
 
-func applyOperation(value int, op models.Operation) (int, error) {
-	switch op.Operator {
+func applyOperation(value int, operator models.Operator, operand int) (int, error) {
+	switch operator {
 	case models.OperatorPlus:
-		return value + op.Value, nil
+		return value + operand, nil
 	case models.OperatorMinus:
-		return value - op.Value, nil
+		return value - operand, nil
 	case models.OperatorMultiply:
-		return value * op.Value, nil
+		return value * operand, nil
 	case models.OperatorDivide:
-		if op.Value == 0 {
+		if operand == 0 {
 			return 0, ErrDivisionByZero
 		}
-		// Check if division would cause integer overflow
-		if value == -1<<31 && op.Value == -1 {
-			return 0, errors.New("division would cause integer overflow")
+		// The only quotient that overflows int, which Go would wrap silently
+		if value == math.MinInt && operand == -1 {
+			return 0, ErrOverflow
 		}
-		return value / op.Value, nil
+		return value / operand, nil
 	default:
 		return 0, ErrInvalidOperator
 	}
@@ -1,6 +1,8 @@
 package processor
 
 import (
+	"errors"
+	"math"
 	"testing"
 
 	"concurrent-pipeline-processor/pkg/models"
@@ -45,18 +47,28 @@ func TestProcessTask(t *testing.T) {
 				},
 			},
 			wantErr: true,
-			errMsg:  "division by zero",
+			errMsg:  "operation 0: division by zero",
 		},
 		{
 			name: "division overflow check",
 			task: models.Task{
-				Value: -1 << 31, // Minimum int32 value
+				Value: math.MinInt,
 				Operations: []models.Operation{
 					{Operator: models.OperatorDivide, Value: -1},
 				},
 			},
 			wantErr: true,
-			errMsg:  "division would cause integer overflow",
+			errMsg:  "operation 0: integer overflow",
+		},
+		{
+			name: "division of the minimum int32 value",
+			task: models.Task{
+				Value: -1 << 31,
+				Operations: []models.Operation{
+					{Operator: models.OperatorDivide, Value: -1},
+				},
+			},
+			want: 1 << 31,
 		},
 		{
 			name: "valid division",
@@ -77,7 +89,7 @@ func TestProcessTask(t *testing.T) {
 				},
 			},
 			wantErr: true,
-			errMsg:  "invalid operator",
+			errMsg:  "operation 0: invalid operator",
 		},
 		{
 			name: "complex calculation with division",
@@ -120,3 +132,26 @@ func TestProcessTask(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessTaskError(t *testing.T) {
+	task := models.Task{
+		ID:    "t-1",
+		Value: 10,
+		Operations: []models.Operation{
+			{Operator: models.OperatorPlus, Value: 1},
+			{Operator: models.OperatorDivide, Value: 0},
+		},
+	}
+
+	result := ProcessTask(task)
+	if !errors.Is(result.Error, ErrDivisionByZero) {
+		t.Fatalf("Expected ErrDivisionByZero, got %v", result.Error)
+	}
+	var err *models.Error
+	if !errors.As(result.Error, &err) {
+		t.Fatalf("Expected *models.Error, got %T", result.Error)
+	}
+	if err.Code != models.CodeDivisionByZero || err.Stage != models.StageProcessor || err.TaskID != "t-1" || err.Index != 1 {
+		t.Errorf("Expected division_by_zero/processor/t-1/1, got %s/%s/%s/%d", err.Code, err.Stage, err.TaskID, err.Index)
+	}
+}
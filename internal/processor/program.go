@@ -0,0 +1,261 @@
+package processor
+
+import (
+	"container/list"
+	"math"
+	"slices"
+	"sync"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+// Program is an operation list compiled for native int arithmetic. It gives
+// the same results and errors as ProcessTask for any initial value, but runs
+// of additions, subtractions and multiplications are folded into a single
+// step computing value*mul + add, along with the division following them,
+// identity operations are dropped, and operations that always fail are found
+// once at compile time. Operation lists with control steps are not optimised.
+type Program struct {
+	// ops holds the operation list when it has control steps, in which case
+	// it is run as is
+	ops   []models.Operation
+	steps []step
+	// err is the error of the first operation that fails for every initial
+	// value, at index errIndex; the steps stop before it
+	err      error
+	errIndex int
+}
+
+// step computes value*mul + add, then divides it by divisor unless divisor
+// is 0
+type step struct {
+	mul, add int
+	divisor  int
+	// index is the position of the division, the only operation that can fail
+	index int
+}
+
+// Compile compiles an operation list into a program. Folding is exact since
+// int arithmetic wraps around, so (v*a + b)*c + d equals v*(a*c) + (b*c + d)
+// even when the intermediate values overflow.
+func Compile(ops []models.Operation) *Program {
+	if slices.ContainsFunc(ops, models.Operation.IsControl) {
+		return &Program{ops: ops}
+	}
+
+	p := &Program{steps: make([]step, 0, len(ops))}
+
+	for i, op := range ops {
+		switch op.Operator {
+		case models.OperatorPlus:
+			p.affine(1, op.Value)
+		case models.OperatorMinus:
+			p.affine(1, -op.Value)
+		case models.OperatorMultiply:
+			p.affine(op.Value, 0)
+		case models.OperatorDivide:
+			if op.Value == 0 {
+				p.err, p.errIndex = ErrDivisionByZero, i
+				return p
+			}
+			p.divide(op.Value, i)
+		default:
+			p.err, p.errIndex = ErrInvalidOperator, i
+			return p
+		}
+	}
+
+	return p
+}
+
+// affine folds value*mul + add into the last step unless it divides,
+// dropping steps that leave values unchanged
+func (p *Program) affine(mul, add int) {
+	s := step{mul: 1}
+	if n := len(p.steps); n > 0 && p.steps[n-1].divisor == 0 {
+		s = p.steps[n-1]
+		p.steps = p.steps[:n-1]
+	}
+	s.mul, s.add = s.mul*mul, s.add*mul+add
+	if s.mul != 1 || s.add != 0 {
+		p.steps = append(p.steps, s)
+	}
+}
+
+// divide sets the divisor of the last step unless it already divides.
+// Dividing by 1 leaves values unchanged and never overflows, so it is dropped.
+func (p *Program) divide(divisor, index int) {
+	if divisor == 1 {
+		return
+	}
+	if n := len(p.steps); n > 0 && p.steps[n-1].divisor == 0 {
+		p.steps[n-1].divisor, p.steps[n-1].index = divisor, index
+		return
+	}
+	p.steps = append(p.steps, step{mul: 1, divisor: divisor, index: index})
+}
+
+// Run evaluates the program for an initial value. A failing operation is
+// reported as a *models.Error holding its index.
+func (p *Program) Run(value int) (int, error) {
+	result, index, err := p.run(value)
+	if err != nil {
+		return 0, models.WrapError(err, models.StageProcessor, "", index)
+	}
+	return result, nil
+}
+
+// ProcessTask processes task like ProcessTask; the task's operations must be
+// those the program was compiled from
+func (p *Program) ProcessTask(task models.Task) models.Result {
+	result, index, err := p.run(task.Value)
+	if err != nil {
+		return models.Result{Error: models.WrapError(err, models.StageProcessor, task.ID, index)}
+	}
+	return models.Result{Result: result}
+}
+
+func (p *Program) run(value int) (int, int, error) {
+	if p.ops != nil {
+		return processInt(value, p.ops)
+	}
+	for i := range p.steps {
+		s := &p.steps[i]
+		value = value*s.mul + s.add
+		if s.divisor == 0 {
+			continue
+		}
+		// Same overflow check as applyOperation
+		if value == math.MinInt && s.divisor == -1 {
+			return 0, s.index, ErrOverflow
+		}
+		value /= s.divisor
+	}
+	if p.err != nil {
+		return 0, p.errIndex, p.err
+	}
+	return value, 0, nil
+}
+
+// ProgramCacheStats reports how often a ProgramCache found a compiled program
+type ProgramCacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// ProgramCache is a least recently used cache of compiled programs, keyed by
+// a hash of the operation list. It is safe for concurrent use. Operation
+// lists with control steps are compiled on every call, since their programs
+// are not optimised.
+type ProgramCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *programEntry, most recently used first
+	entries map[uint64]*list.Element
+	stats   ProgramCacheStats
+}
+
+type programEntry struct {
+	key     uint64
+	ops     []models.Operation
+	program *Program
+}
+
+// NewProgramCache creates a cache holding at most size programs; size must be
+// greater than 0
+func NewProgramCache(size int) *ProgramCache {
+	return &ProgramCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[uint64]*list.Element, size),
+	}
+}
+
+// Program returns the compiled program for ops, compiling and caching it on
+// a miss
+func (c *ProgramCache) Program(ops []models.Operation) *Program {
+	key, ok := hashOperations(ops)
+	if !ok {
+		return Compile(ops)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*programEntry)
+		// Compare the operations too, so a hash collision is only a miss
+		if entry.matches(ops) {
+			c.stats.Hits++
+			c.order.MoveToFront(elem)
+			return entry.program
+		}
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+
+	c.stats.Misses++
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*programEntry).key)
+	}
+	ops = slices.Clone(ops)
+	entry := &programEntry{key: key, ops: ops, program: Compile(ops)}
+	c.entries[key] = c.order.PushFront(entry)
+	return entry.program
+}
+
+// matches reports whether ops are the operations the entry was compiled
+// from, ignoring float operands; cached operations never have control steps
+func (e *programEntry) matches(ops []models.Operation) bool {
+	if len(e.ops) != len(ops) {
+		return false
+	}
+	for i := range ops {
+		op, c := &ops[i], &e.ops[i]
+		if op.Value != c.Value || op.Operator != c.Operator || op.Control != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ProcessTask processes task like ProcessTask, using the cached program for
+// its operations
+func (c *ProgramCache) ProcessTask(task models.Task) models.Result {
+	return c.Program(task.Operations).ProcessTask(task)
+}
+
+// Len returns the number of cached programs
+func (c *ProgramCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the number of cache hits and misses so far
+func (c *ProgramCache) Stats() ProgramCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// hashOperations returns a 64-bit FNV-1a style hash of the operators and int
+// operands, mixing a whole operation per step. It reports false for lists
+// with control steps, which are not cached.
+func hashOperations(ops []models.Operation) (uint64, bool) {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for _, op := range ops {
+		if op.Control != nil {
+			return 0, false
+		}
+		// Operators fit in the top bits, which small operands leave unused
+		h = (h ^ uint64(op.Value) ^ uint64(op.Operator)<<60) * prime64
+	}
+	return h, true
+}
@@ -0,0 +1,235 @@
+package processor
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"concurrent-pipeline-processor/pkg/models"
+)
+
+func TestCompile(t *testing.T) {
+	op := func(operator models.Operator, value int) models.Operation {
+		return models.Operation{Operator: operator, Value: value}
+	}
+
+	tests := []struct {
+		name      string
+		ops       []models.Operation
+		wantSteps int
+		wantErr   error
+	}{
+		{name: "empty", ops: []models.Operation{}, wantSteps: 0},
+		{
+			name:      "folds additions and subtractions",
+			ops:       []models.Operation{op(models.OperatorPlus, 5), op(models.OperatorMinus, 3), op(models.OperatorPlus, 1)},
+			wantSteps: 1,
+		},
+		{
+			name:      "folds multiplications",
+			ops:       []models.Operation{op(models.OperatorMultiply, 2), op(models.OperatorMultiply, 3)},
+			wantSteps: 1,
+		},
+		{
+			name:      "folds mixed operations",
+			ops:       []models.Operation{op(models.OperatorPlus, 17), op(models.OperatorMultiply, 3), op(models.OperatorMinus, 42)},
+			wantSteps: 1,
+		},
+		{
+			name:      "folds divisions into the preceding step",
+			ops:       []models.Operation{op(models.OperatorPlus, 1), op(models.OperatorDivide, 2), op(models.OperatorMultiply, 3), op(models.OperatorPlus, 1)},
+			wantSteps: 2,
+		},
+		{
+			name:      "drops identities",
+			ops:       []models.Operation{op(models.OperatorPlus, 2), op(models.OperatorMinus, 2), op(models.OperatorDivide, 1)},
+			wantSteps: 0,
+		},
+		{
+			name:      "folds across dropped identities",
+			ops:       []models.Operation{op(models.OperatorMultiply, 2), op(models.OperatorPlus, 0), op(models.OperatorMultiply, 3)},
+			wantSteps: 1,
+		},
+		{
+			name:      "keeps divisions",
+			ops:       []models.Operation{op(models.OperatorDivide, 2), op(models.OperatorDivide, 3)},
+			wantSteps: 2,
+		},
+		{
+			name:      "stops at a zero divisor",
+			ops:       []models.Operation{op(models.OperatorPlus, 1), op(models.OperatorDivide, 0), op(models.OperatorMultiply, 2)},
+			wantSteps: 1,
+			wantErr:   ErrDivisionByZero,
+		},
+		{
+			name:      "stops at an invalid operator",
+			ops:       []models.Operation{op(models.OperatorTotalAmount, 1)},
+			wantSteps: 0,
+			wantErr:   ErrInvalidOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Compile(tt.ops)
+			if len(p.steps) != tt.wantSteps {
+				t.Errorf("Expected %d steps, got %+v", tt.wantSteps, p.steps)
+			}
+			_, err := p.Run(7)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestProgramMatchesProcessTask(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	operands := []int{0, 1, -1, 2, -3, 7, 1 << 40, math.MaxInt, math.MinInt}
+	values := []int{0, 1, -1, 1000, -1 << 31, math.MaxInt, math.MinInt}
+
+	for i := 0; i < 2000; i++ {
+		ops := make([]models.Operation, rng.Intn(8))
+		for j := range ops {
+			ops[j] = models.Operation{
+				Operator: models.Operator(rng.Intn(int(models.OperatorTotalAmount))),
+				Value:    operands[rng.Intn(len(operands))],
+			}
+		}
+		p := Compile(ops)
+
+		for _, v := range values {
+			task := models.Task{ID: "t", Value: v, Operations: ops}
+			want, got := ProcessTask(task), p.ProcessTask(task)
+			if got.Result != want.Result || models.CodeOf(got.Error) != models.CodeOf(want.Error) {
+				t.Fatalf("Expected %+v, got %+v for %d %+v", want, got, v, ops)
+			}
+			if want.Error != nil && got.Error.Error() != want.Error.Error() {
+				t.Fatalf("Expected error %q, got %q", want.Error, got.Error)
+			}
+		}
+	}
+}
+
+func TestProgramCache(t *testing.T) {
+	opsA := []models.Operation{{Operator: models.OperatorPlus, Value: 1}}
+	opsB := []models.Operation{{Operator: models.OperatorMultiply, Value: 2}}
+	opsC := []models.Operation{{Operator: models.OperatorMinus, Value: 3}}
+
+	c := NewProgramCache(2)
+	pa := c.Program(opsA)
+	if c.Program(opsA) != pa {
+		t.Error("Expected the cached program for a repeated operation list")
+	}
+	c.Program(opsB)
+	c.Program(opsA) // opsB is now least recently used
+	c.Program(opsC)
+
+	if got := c.Len(); got != 2 {
+		t.Errorf("Expected 2 cached programs, got %d", got)
+	}
+	if c.Program(opsA) != pa {
+		t.Error("Expected the recently used program to stay cached")
+	}
+	if got, want := c.Stats(), (ProgramCacheStats{Hits: 3, Misses: 3}); got != want {
+		t.Errorf("Expected stats %+v, got %+v", want, got)
+	}
+	c.Program(opsB)
+	if got := c.Stats().Misses; got != 4 {
+		t.Errorf("Expected the evicted program to miss, got %d misses", got)
+	}
+
+	result := c.ProcessTask(models.Task{Value: 10, Operations: opsC})
+	if result.Error != nil || result.Result != 7 {
+		t.Errorf("Expected 7, got %+v", result)
+	}
+
+	// Operation lists differing only in their control steps are told apart
+	misses := c.Stats().Misses
+	clamp := func(hi int) []models.Operation {
+		return []models.Operation{{Control: &models.Control{Clamp: &models.Clamp{Max: &hi}}}}
+	}
+	if got := c.ProcessTask(models.Task{Value: 10, Operations: clamp(5)}); got.Result != 5 {
+		t.Errorf("Expected 5, got %+v", got)
+	}
+	if got := c.ProcessTask(models.Task{Value: 10, Operations: clamp(8)}); got.Result != 8 {
+		t.Errorf("Expected 8, got %+v", got)
+	}
+	if got := c.Stats().Misses; got != misses {
+		t.Errorf("Expected control steps not to be cached, got %d misses", got-misses)
+	}
+
+	// Copies of a list, with or without float operands, share its program
+	c = NewProgramCache(2)
+	pa = c.Program(opsA)
+	float := 2.5
+	copyA := []models.Operation{{Operator: models.OperatorPlus, Value: 1, Float: &float}}
+	if c.Program(copyA) != pa || c.Program(copyA) != pa {
+		t.Error("Expected a copy of a cached operation list to share its program")
+	}
+
+	// A list changed in place is not mistaken for the list it was
+	opsA[0].Value = 2
+	if got := c.ProcessTask(models.Task{Value: 10, Operations: opsA}); got.Result != 12 {
+		t.Errorf("Expected 12 for a changed operation list, got %+v", got)
+	}
+	if got := c.Stats().Misses; got != 2 {
+		t.Errorf("Expected the changed list to miss, got %d misses", got)
+	}
+}
+
+// longBenchmarkTask repeats the operations of benchmarkTask, as rule sets
+// with many steps would
+func longBenchmarkTask() models.Task {
+	task := benchmarkTask()
+	ops := task.Operations
+	for len(task.Operations) < 48 {
+		task.Operations = append(task.Operations, ops...)
+	}
+	return task
+}
+
+func BenchmarkProcessTaskLong(b *testing.B) {
+	task := longBenchmarkTask()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ProcessTask(task)
+	}
+}
+
+func BenchmarkProgramRun(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		task models.Task
+	}{
+		{name: "short", task: benchmarkTask()},
+		{name: "long", task: longBenchmarkTask()},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			p := Compile(bm.task.Operations)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				p.ProcessTask(bm.task)
+			}
+		})
+	}
+}
+
+func BenchmarkProgramCache(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		task models.Task
+	}{
+		{name: "short", task: benchmarkTask()},
+		{name: "long", task: longBenchmarkTask()},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			c := NewProgramCache(16)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.ProcessTask(bm.task)
+			}
+		})
+	}
+}
@@ -3,37 +3,350 @@ package processor
 import (
 	"concurrent-pipeline-processor/pkg/models"
 	"errors"
+	"fmt"
+	"math"
+	"strings"
 )
 
 var (
-	ErrNilOperations   = errors.New("operations slice is nil")
-	ErrInvalidOperator = errors.New("invalid operator")
+	ErrNilOperations      = models.NewError(models.CodeNilOperations, "operations slice is nil")
+	ErrInvalidOperator    = models.NewError(models.CodeInvalidOperator, "invalid operator")
+	ErrEmptyOperations    = models.NewError(models.CodeEmptyOperations, "operations slice is empty")
+	ErrTooManyOperations  = models.NewError(models.CodeTooManyOperations, "too many operations")
+	ErrValueOutOfRange    = models.NewError(models.CodeValueOutOfRange, "value out of range")
+	ErrOperandOutOfRange  = models.NewError(models.CodeOperandOutOfRange, "operand out of range")
+	ErrOperatorNotAllowed = models.NewError(models.CodeOperatorNotAllowed, "operator not allowed")
+	ErrOverflow           = models.NewError(models.CodeOverflow, "integer overflow")
+	ErrPossibleOverflow   = models.NewError(models.CodePossibleOverflow, "possible integer overflow")
+	ErrNaN                = models.NewError(models.CodeNaN, "value is not a number")
+	ErrInfinity           = models.NewError(models.CodeInfinity, "value is infinite")
+	ErrInvalidControl     = models.NewError(models.CodeInvalidControl, "control step must set exactly one of if, clamp and exit")
+	ErrInvalidComparison  = models.NewError(models.CodeInvalidControl, "invalid comparison")
+	ErrEmptyBranches      = models.NewError(models.CodeInvalidControl, "if step has no operations")
+	ErrInvalidClamp       = models.NewError(models.CodeInvalidControl, "clamp bounds are missing or inverted")
+	ErrNestingTooDeep     = models.NewError(models.CodeNestingTooDeep, "if steps are nested too deeply")
+
+	// ErrInvalidMaxOperations is returned when the operation limit is negative
+	ErrInvalidMaxOperations = errors.New("max operations must not be negative")
+	// ErrInvalidValueRange is returned when the minimum value exceeds the maximum
+	ErrInvalidValueRange = errors.New("min value must not exceed max value")
+	// ErrInvalidOperandRange is returned when the minimum operand exceeds the maximum
+	ErrInvalidOperandRange = errors.New("min operand must not exceed max operand")
 )
 
-// ValidateTask validates a task and its operations
+// Rules are data contract checks applied on top of the built-in ones. The
+// zero value applies no extra checks.
+type Rules struct {
+	// MaxOperations limits the number of operations per task, counting those
+	// in if branches; zero means no limit
+	MaxOperations int
+	// MinValue and MaxValue bound the initial task value when set
+	MinValue *int
+	MaxValue *int
+	// MinOperand and MaxOperand bound every operation value when set
+	MinOperand *int
+	MaxOperand *int
+	// AllowedOperators lists the operators tasks may use; empty allows all
+	AllowedOperators []models.Operator
+	// RejectEmptyOperations rejects tasks without operations
+	RejectEmptyOperations bool
+	// RejectPossibleOverflow rejects tasks whose operations might overflow
+	// for any initial value allowed by MinValue and MaxValue, not only for
+	// the task's own value
+	RejectPossibleOverflow bool
+	// Mode is the arithmetic tasks are evaluated with; overflow is only
+	// checked in ModeInt
+	Mode Mode
+}
+
+// Validate checks if the rules are consistent
+func (r Rules) Validate() error {
+	if r.MaxOperations < 0 {
+		return ErrInvalidMaxOperations
+	}
+	if r.MinValue != nil && r.MaxValue != nil && *r.MinValue > *r.MaxValue {
+		return ErrInvalidValueRange
+	}
+	if r.MinOperand != nil && r.MaxOperand != nil && *r.MinOperand > *r.MaxOperand {
+		return ErrInvalidOperandRange
+	}
+	for _, op := range r.AllowedOperators {
+		if op < 0 || op >= models.OperatorTotalAmount {
+			return ErrInvalidOperator
+		}
+	}
+	return r.Mode.Validate()
+}
+
+// Violation describes one problem found in a task
+type Violation struct {
+	// Index is the position of the offending operation, or -1 if the
+	// violation concerns the task as a whole
+	Index int
+	// Operator and Value are those of the offending operation
+	Operator models.Operator
+	Value    int
+	// Code identifies the kind of violation
+	Code models.ErrorCode
+	// Err is the sentinel error for the violation
+	Err error
+}
+
+func (v Violation) Error() string {
+	if v.Index < 0 {
+		return v.Err.Error()
+	}
+	return fmt.Sprintf("operation %d: %v", v.Index, v.Err)
+}
+
+// ValidationError lists every violation found in a task. errors.Is matches
+// the sentinel error of each violation, and errors.As to a *models.Error
+// yields the first violation.
+type ValidationError struct {
+	// TaskID is the ID of the invalid task
+	TaskID     string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 1 {
+		return e.Violations[0].Error()
+	}
+
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Error()
+	}
+	return fmt.Sprintf("%d validation errors: %s", len(e.Violations), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the violations as *models.Error values wrapping their
+// sentinel errors
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Violations))
+	for i, v := range e.Violations {
+		errs[i] = models.WrapError(v.Err, models.StageValidator, e.TaskID, v.Index)
+	}
+	return errs
+}
+
+// ValidateTask validates a task and its operations against the built-in
+// checks only, returning a *ValidationError listing every violation found
 func ValidateTask(task models.Task) error {
+	return Rules{}.ValidateTask(task)
+}
+
+// ValidateTask validates a task against the built-in checks and the rules,
+// returning a *ValidationError listing every violation found
+func (r Rules) ValidateTask(task models.Task) error {
 	if task.Operations == nil {
-		return ErrNilOperations
+		return &ValidationError{TaskID: task.ID, Violations: []Violation{
+			{Index: -1, Code: models.CodeNilOperations, Err: ErrNilOperations},
+		}}
+	}
+
+	var violations []Violation
+	taskViolation := func(err error) {
+		violations = append(violations, Violation{Index: -1, Value: task.Value, Code: models.CodeOf(err), Err: err})
 	}
 
-	for _, op := range task.Operations {
-		if err := validateOperation(op); err != nil {
-			return err
+	if r.RejectEmptyOperations && len(task.Operations) == 0 {
+		taskViolation(ErrEmptyOperations)
+	}
+	if r.MaxOperations > 0 && countOperations(task.Operations) > r.MaxOperations {
+		taskViolation(ErrTooManyOperations)
+	}
+	if r.Mode == ModeFloat {
+		v := task.FloatValue()
+		if err := checkFinite(v); err != nil {
+			taskViolation(err)
+		} else if !inRangeFloat(v, r.MinValue, r.MaxValue) {
+			taskViolation(ErrValueOutOfRange)
 		}
+	} else if !inRange(task.Value, r.MinValue, r.MaxValue) {
+		taskViolation(ErrValueOutOfRange)
 	}
 
+	for i, op := range task.Operations {
+		violations = r.validateOperation(i, 0, op, violations)
+	}
+	violations = r.validateRange(task, violations)
+
+	if len(violations) > 0 {
+		return &ValidationError{TaskID: task.ID, Violations: violations}
+	}
 	return nil
 }
 
-func validateOperation(op models.Operation) error {
+// validateOperation appends the violations of op, nested in depth if steps
+// of the top-level operation at index i
+func (r Rules) validateOperation(i, depth int, op models.Operation, violations []Violation) []Violation {
+	add := func(err error) {
+		violations = append(violations, Violation{Index: i, Operator: op.Operator, Value: op.Value, Code: models.CodeOf(err), Err: err})
+	}
+
+	if op.IsControl() {
+		if err := validateControl(op.Control); err != nil {
+			add(err)
+			return violations
+		}
+		if op.Control.If == nil {
+			return violations
+		}
+		if depth >= MaxNestingDepth {
+			add(ErrNestingTooDeep)
+			return violations
+		}
+		for _, nested := range op.Control.If.Then {
+			violations = r.validateOperation(i, depth+1, nested, violations)
+		}
+		for _, nested := range op.Control.If.Else {
+			violations = r.validateOperation(i, depth+1, nested, violations)
+		}
+		return violations
+	}
+
 	if op.Operator < 0 || op.Operator >= models.OperatorTotalAmount {
-		return ErrInvalidOperator
+		add(ErrInvalidOperator)
+		return violations
+	}
+	if !r.allowed(op.Operator) {
+		add(ErrOperatorNotAllowed)
+	}
+
+	if r.Mode == ModeFloat {
+		r.validateFloatOperand(op, add)
+		return violations
 	}
 
 	// For division, check if divisor is zero
 	if op.Operator == models.OperatorDivide && op.Value == 0 {
-		return errors.New("division by zero")
+		add(ErrDivisionByZero)
+	}
+	if !inRange(op.Value, r.MinOperand, r.MaxOperand) {
+		add(ErrOperandOutOfRange)
+	}
+
+	return violations
+}
+
+// validateFloatOperand reports the violations of the float operand of op,
+// which must be finite
+func (r Rules) validateFloatOperand(op models.Operation, add func(error)) {
+	v := op.FloatValue()
+	if err := checkFinite(v); err != nil {
+		add(err)
+		return
 	}
+	if op.Operator == models.OperatorDivide && v == 0 {
+		add(ErrDivisionByZero)
+	}
+	if !inRangeFloat(v, r.MinOperand, r.MaxOperand) {
+		add(ErrOperandOutOfRange)
+	}
+}
 
+// validateControl checks that a control step is well formed, not looking
+// into if branches
+func validateControl(c *models.Control) error {
+	set := 0
+	for _, isSet := range []bool{c.If != nil, c.Clamp != nil, c.Exit != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return ErrInvalidControl
+	}
+
+	switch {
+	case c.If != nil:
+		if !validComparison(c.If.Compare) {
+			return ErrInvalidComparison
+		}
+		if len(c.If.Then) == 0 && len(c.If.Else) == 0 {
+			return ErrEmptyBranches
+		}
+	case c.Clamp != nil:
+		if (c.Clamp.Min == nil && c.Clamp.Max == nil) ||
+			(c.Clamp.Min != nil && c.Clamp.Max != nil && *c.Clamp.Min > *c.Clamp.Max) {
+			return ErrInvalidClamp
+		}
+	case c.Exit != nil:
+		if !validComparison(c.Exit.Compare) {
+			return ErrInvalidComparison
+		}
+	}
 	return nil
 }
+
+func validComparison(c models.Comparison) bool {
+	return c >= 0 && c < models.CompareTotalAmount
+}
+
+// countOperations returns the number of operations in ops, including those
+// in if branches
+func countOperations(ops []models.Operation) int {
+	n := len(ops)
+	for _, op := range ops {
+		if op.Control != nil && op.Control.If != nil {
+			n += countOperations(op.Control.If.Then) + countOperations(op.Control.If.Else)
+		}
+	}
+	return n
+}
+
+// validateRange appends a violation for the first operation that overflows
+// for the task's value or, with RejectPossibleOverflow, might overflow for
+// any allowed value
+func (r Rules) validateRange(task models.Task, violations []Violation) []Violation {
+	if r.Mode != ModeInt {
+		return violations
+	}
+
+	add := func(i int, err *models.Error) []Violation {
+		op := task.Operations[i]
+		return append(violations, Violation{Index: i, Operator: op.Operator, Value: op.Value, Code: err.Code, Err: err})
+	}
+
+	if analysis := AnalyzeRange(Point(task.Value), task.Operations); analysis.Index >= 0 {
+		return add(analysis.Index, ErrOverflow)
+	}
+	if !r.RejectPossibleOverflow {
+		return violations
+	}
+
+	values := Interval{Min: math.MinInt, Max: math.MaxInt}
+	if r.MinValue != nil {
+		values.Min = *r.MinValue
+	}
+	if r.MaxValue != nil {
+		values.Max = *r.MaxValue
+	}
+	if analysis := AnalyzeRange(values, task.Operations); analysis.Index >= 0 {
+		return add(analysis.Index, ErrPossibleOverflow)
+	}
+	return violations
+}
+
+func (r Rules) allowed(op models.Operator) bool {
+	if len(r.AllowedOperators) == 0 {
+		return true
+	}
+	for _, a := range r.AllowedOperators {
+		if a == op {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange reports whether v lies within the optional inclusive bounds
+func inRange(v int, lo, hi *int) bool {
+	return (lo == nil || v >= *lo) && (hi == nil || v <= *hi)
+}
+
+// inRangeFloat reports whether v lies within the optional inclusive bounds
+func inRangeFloat(v float64, lo, hi *int) bool {
+	return (lo == nil || v >= float64(*lo)) && (hi == nil || v <= float64(*hi))
+}
@@ -1,6 +1,8 @@
 package processor
 
 import (
+	"errors"
+	"math"
 	"testing"
 
 	"concurrent-pipeline-processor/pkg/models"
@@ -41,7 +43,7 @@ func TestValidateTask(t *testing.T) {
 				},
 			},
 			wantErr: true,
-			errMsg:  "invalid operator",
+			errMsg:  "operation 0: invalid operator",
 		},
 		{
 			name: "division by zero",
@@ -52,7 +54,20 @@ func TestValidateTask(t *testing.T) {
 				},
 			},
 			wantErr: true,
-			errMsg:  "division by zero",
+			errMsg:  "operation 0: division by zero",
+		},
+		{
+			name: "multiple violations",
+			task: models.Task{
+				Value: 5,
+				Operations: []models.Operation{
+					{Operator: models.OperatorDivide, Value: 0},
+					{Operator: models.OperatorPlus, Value: 1},
+					{Operator: -1, Value: 2},
+				},
+			},
+			wantErr: true,
+			errMsg:  "2 validation errors: operation 0: division by zero; operation 2: invalid operator",
 		},
 	}
 
@@ -77,3 +92,320 @@ func TestValidateTask(t *testing.T) {
 		})
 	}
 }
+
+func TestValidationReport(t *testing.T) {
+	task := models.Task{
+		Value: 1,
+		Operations: []models.Operation{
+			{Operator: models.OperatorTotalAmount, Value: 3},
+			{Operator: models.OperatorMultiply, Value: 2},
+			{Operator: models.OperatorDivide, Value: 0},
+		},
+	}
+
+	err := ValidateTask(task)
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %T", err)
+	}
+	want := []Violation{
+		{Index: 0, Operator: models.OperatorTotalAmount, Value: 3, Code: models.CodeInvalidOperator, Err: ErrInvalidOperator},
+		{Index: 2, Operator: models.OperatorDivide, Value: 0, Code: models.CodeDivisionByZero, Err: ErrDivisionByZero},
+	}
+	if len(verr.Violations) != len(want) {
+		t.Fatalf("Expected %d violations, got %+v", len(want), verr.Violations)
+	}
+	for i, v := range verr.Violations {
+		if v != want[i] {
+			t.Errorf("Expected violation %+v, got %+v", want[i], v)
+		}
+	}
+
+	for _, target := range []error{ErrInvalidOperator, ErrDivisionByZero} {
+		if !errors.Is(err, target) {
+			t.Errorf("Expected errors.Is(err, %v) to be true", target)
+		}
+	}
+	if errors.Is(err, ErrNilOperations) {
+		t.Error("Expected errors.Is(err, ErrNilOperations) to be false")
+	}
+
+	err = ValidateTask(models.Task{Value: 1})
+	if !errors.Is(err, ErrNilOperations) {
+		t.Errorf("Expected ErrNilOperations, got %v", err)
+	}
+	if errors.As(err, &verr) && verr.Violations[0].Code != models.CodeNilOperations {
+		t.Errorf("Expected code %s, got %s", models.CodeNilOperations, verr.Violations[0].Code)
+	}
+}
+
+func TestRules(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+	ops := func(ops ...models.Operation) []models.Operation { return append([]models.Operation{}, ops...) }
+
+	tests := []struct {
+		name      string
+		rules     Rules
+		task      models.Task
+		wantCodes []models.ErrorCode
+	}{
+		{
+			name:  "zero rules allow empty operations",
+			task:  models.Task{Value: 1, Operations: ops()},
+			rules: Rules{},
+		},
+		{
+			name:      "rejects empty operations",
+			rules:     Rules{RejectEmptyOperations: true},
+			task:      models.Task{Value: 1, Operations: ops()},
+			wantCodes: []models.ErrorCode{models.CodeEmptyOperations},
+		},
+		{
+			name:  "rejects too many operations",
+			rules: Rules{MaxOperations: 1},
+			task: models.Task{Value: 1, Operations: ops(
+				models.Operation{Operator: models.OperatorPlus, Value: 1},
+				models.Operation{Operator: models.OperatorPlus, Value: 2},
+			)},
+			wantCodes: []models.ErrorCode{models.CodeTooManyOperations},
+		},
+		{
+			name:      "rejects value out of range",
+			rules:     Rules{MinValue: intPtr(0), MaxValue: intPtr(10)},
+			task:      models.Task{Value: 11, Operations: ops()},
+			wantCodes: []models.ErrorCode{models.CodeValueOutOfRange},
+		},
+		{
+			name:  "reports every operation violation",
+			rules: Rules{MaxOperand: intPtr(100), AllowedOperators: []models.Operator{models.OperatorPlus, models.OperatorDivide}},
+			task: models.Task{Value: 1, Operations: ops(
+				models.Operation{Operator: models.OperatorMultiply, Value: 200},
+				models.Operation{Operator: models.OperatorPlus, Value: 5},
+				models.Operation{Operator: models.OperatorDivide, Value: 0},
+			)},
+			wantCodes: []models.ErrorCode{models.CodeOperatorNotAllowed, models.CodeOperandOutOfRange, models.CodeDivisionByZero},
+		},
+		{
+			name: "rejects overflow for the task value",
+			task: models.Task{Value: math.MaxInt, Operations: ops(
+				models.Operation{Operator: models.OperatorMinus, Value: 1},
+				models.Operation{Operator: models.OperatorPlus, Value: 2},
+			)},
+			wantCodes: []models.ErrorCode{models.CodeOverflow},
+		},
+		{
+			name:  "skips overflow checks in arbitrary precision mode",
+			rules: Rules{Mode: ModeBig, RejectPossibleOverflow: true},
+			task: models.Task{Value: math.MaxInt, Operations: ops(
+				models.Operation{Operator: models.OperatorMultiply, Value: 2},
+			)},
+		},
+		{
+			name:  "rejects possible overflow over the value range",
+			rules: Rules{MinValue: intPtr(0), MaxValue: intPtr(math.MaxInt / 2), RejectPossibleOverflow: true},
+			task: models.Task{Value: 1, Operations: ops(
+				models.Operation{Operator: models.OperatorPlus, Value: 1},
+				models.Operation{Operator: models.OperatorMultiply, Value: 3},
+			)},
+			wantCodes: []models.ErrorCode{models.CodePossibleOverflow},
+		},
+		{
+			name:  "allows possible overflow by default",
+			rules: Rules{MinValue: intPtr(0), MaxValue: intPtr(math.MaxInt / 2)},
+			task: models.Task{Value: 1, Operations: ops(
+				models.Operation{Operator: models.OperatorMultiply, Value: 3},
+			)},
+		},
+		{
+			name:  "rejects non-finite floats in float mode",
+			rules: Rules{Mode: ModeFloat},
+			task: models.Task{Float: floatPtr(math.Inf(1)), Operations: ops(
+				models.Operation{Operator: models.OperatorPlus, Float: floatPtr(math.NaN())},
+				models.Operation{Operator: models.OperatorDivide, Value: 2, Float: floatPtr(0)},
+			)},
+			wantCodes: []models.ErrorCode{models.CodeInfinity, models.CodeNaN, models.CodeDivisionByZero},
+		},
+		{
+			name:  "checks float operands against the operand range",
+			rules: Rules{Mode: ModeFloat, MaxOperand: intPtr(1)},
+			task: models.Task{Float: floatPtr(0.5), Operations: ops(
+				models.Operation{Operator: models.OperatorMultiply, Float: floatPtr(1.5)},
+			)},
+			wantCodes: []models.ErrorCode{models.CodeOperandOutOfRange},
+		},
+		{
+			name:  "accepts task within rules",
+			rules: Rules{MaxOperations: 2, MinValue: intPtr(0), MinOperand: intPtr(-5), MaxOperand: intPtr(5), RejectEmptyOperations: true},
+			task: models.Task{Value: 0, Operations: ops(
+				models.Operation{Operator: models.OperatorMinus, Value: -5},
+			)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.ValidateTask(tt.task)
+			if len(tt.wantCodes) == 0 {
+				if err != nil {
+					t.Errorf("ValidateTask() unexpected error: %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected *ValidationError, got %v", err)
+			}
+			if len(verr.Violations) != len(tt.wantCodes) {
+				t.Fatalf("Expected codes %v, got %+v", tt.wantCodes, verr.Violations)
+			}
+			for i, v := range verr.Violations {
+				if v.Code != tt.wantCodes[i] {
+					t.Errorf("Expected code %s at %d, got %s", tt.wantCodes[i], i, v.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	low, high := 1, 2
+	tests := []struct {
+		name  string
+		rules Rules
+		want  error
+	}{
+		{name: "zero rules", rules: Rules{}},
+		{name: "negative max operations", rules: Rules{MaxOperations: -1}, want: ErrInvalidMaxOperations},
+		{name: "inverted value range", rules: Rules{MinValue: &high, MaxValue: &low}, want: ErrInvalidValueRange},
+		{name: "inverted operand range", rules: Rules{MinOperand: &high, MaxOperand: &low}, want: ErrInvalidOperandRange},
+		{name: "invalid allowed operator", rules: Rules{AllowedOperators: []models.Operator{models.OperatorTotalAmount}}, want: ErrInvalidOperator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); err != tt.want {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateControl(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	plus := models.Operation{Operator: models.OperatorPlus, Value: 1}
+	ifStep := func(then ...models.Operation) models.Operation {
+		return models.Operation{Control: &models.Control{If: &models.If{
+			Condition: models.Condition{Compare: models.CompareGreater, Value: 0}, Then: then,
+		}}}
+	}
+	nested := func(depth int) models.Operation {
+		op := ifStep(plus)
+		for i := 1; i < depth; i++ {
+			op = ifStep(op)
+		}
+		return op
+	}
+
+	tests := []struct {
+		name     string
+		rules    Rules
+		ops      []models.Operation
+		wantErrs []error
+	}{
+		{
+			name: "well formed steps",
+			ops: []models.Operation{
+				ifStep(plus),
+				{Control: &models.Control{Clamp: &models.Clamp{Max: intPtr(10)}}},
+				{Control: &models.Control{Exit: &models.Condition{Compare: models.CompareEqual, Value: 3}}},
+			},
+		},
+		{
+			name:     "more than one control",
+			ops:      []models.Operation{{Control: &models.Control{Clamp: &models.Clamp{Max: intPtr(1)}, Exit: &models.Condition{}}}},
+			wantErrs: []error{ErrInvalidControl},
+		},
+		{
+			name:     "no control",
+			ops:      []models.Operation{{Control: &models.Control{}}},
+			wantErrs: []error{ErrInvalidControl},
+		},
+		{
+			name:     "invalid comparison",
+			ops:      []models.Operation{{Control: &models.Control{Exit: &models.Condition{Compare: models.CompareTotalAmount}}}},
+			wantErrs: []error{ErrInvalidComparison},
+		},
+		{
+			name:     "empty branches",
+			ops:      []models.Operation{ifStep()},
+			wantErrs: []error{ErrEmptyBranches},
+		},
+		{
+			name:     "inverted clamp",
+			ops:      []models.Operation{{Control: &models.Control{Clamp: &models.Clamp{Min: intPtr(5), Max: intPtr(1)}}}},
+			wantErrs: []error{ErrInvalidClamp},
+		},
+		{
+			name:     "clamp without bounds",
+			ops:      []models.Operation{{Control: &models.Control{Clamp: &models.Clamp{}}}},
+			wantErrs: []error{ErrInvalidClamp},
+		},
+		{
+			name: "deepest allowed nesting",
+			ops:  []models.Operation{nested(MaxNestingDepth)},
+		},
+		{
+			name:     "nesting too deep",
+			ops:      []models.Operation{nested(MaxNestingDepth + 1)},
+			wantErrs: []error{ErrNestingTooDeep},
+		},
+		{
+			name:     "checks operations in branches",
+			rules:    Rules{AllowedOperators: []models.Operator{models.OperatorPlus}},
+			ops:      []models.Operation{plus, ifStep(models.Operation{Operator: models.OperatorDivide, Value: 0})},
+			wantErrs: []error{ErrOperatorNotAllowed, ErrDivisionByZero},
+		},
+		{
+			name:     "counts operations in branches",
+			rules:    Rules{MaxOperations: 2},
+			ops:      []models.Operation{ifStep(plus, plus)},
+			wantErrs: []error{ErrTooManyOperations},
+		},
+		{
+			name: "rejects overflow in the branch taken",
+			ops: []models.Operation{
+				{Operator: models.OperatorPlus, Value: math.MaxInt - 1},
+				ifStep(models.Operation{Operator: models.OperatorPlus, Value: 2}),
+			},
+			wantErrs: []error{ErrOverflow},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.ValidateTask(models.Task{Value: 1, Operations: tt.ops})
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("ValidateTask() unexpected error: %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected *ValidationError, got %v", err)
+			}
+			if len(verr.Violations) != len(tt.wantErrs) {
+				t.Fatalf("Expected %v, got %v", tt.wantErrs, verr)
+			}
+			for i, v := range verr.Violations {
+				if v.Err != tt.wantErrs[i] {
+					t.Errorf("Expected %v at %d, got %v", tt.wantErrs[i], i, v.Err)
+				}
+			}
+		})
+	}
+}
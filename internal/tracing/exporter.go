@@ -0,0 +1,155 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// scopeName is reported as the instrumentation scope of exported spans
+const scopeName = "concurrent-pipeline-processor"
+
+// spanKindInternal is the OTLP span kind used for every exported span
+const spanKindInternal = 1
+
+// JSONExporter writes each span as a single-line OTLP JSON
+// ExportTraceServiceRequest, the format of the OpenTelemetry file exporter
+type JSONExporter struct {
+	mu       sync.Mutex
+	enc      *json.Encoder
+	resource otlpResource
+}
+
+// NewJSONExporter creates an exporter writing to w, reporting serviceName as
+// the service.name resource attribute
+func NewJSONExporter(w io.Writer, serviceName string) *JSONExporter {
+	return &JSONExporter{
+		enc: json.NewEncoder(w),
+		resource: otlpResource{
+			Attributes: otlpAttributes([]Attribute{String("service.name", serviceName)}),
+		},
+	}
+}
+
+// ExportSpan writes span to the underlying writer
+func (e *JSONExporter) ExportSpan(span SpanData) error {
+	req := otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: e.resource,
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: scopeName},
+				Spans: []otlpSpan{toOTLP(span)},
+			}},
+		}},
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.enc.Encode(req)
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Links             []otlpLink     `json:"links,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpLink struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+}
+
+type otlpStatus struct {
+	Code    StatusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// otlpAnyValue sets exactly one field. OTLP JSON encodes 64-bit integers as
+// strings.
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+func toOTLP(span SpanData) otlpSpan {
+	out := otlpSpan{
+		TraceID:           span.SpanContext.TraceID.String(),
+		SpanID:            span.SpanContext.SpanID.String(),
+		Name:              span.Name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+		Attributes:        otlpAttributes(span.Attributes),
+		Status:            otlpStatus{Code: span.Status, Message: span.StatusMessage},
+	}
+	if span.Parent.IsValid() {
+		out.ParentSpanID = span.Parent.String()
+	}
+	for _, link := range span.Links {
+		out.Links = append(out.Links, otlpLink{
+			TraceID: link.TraceID.String(),
+			SpanID:  link.SpanID.String(),
+		})
+	}
+	return out
+}
+
+func otlpAttributes(attrs []Attribute) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		var v otlpAnyValue
+		switch value := attr.Value.(type) {
+		case string:
+			v.StringValue = &value
+		case int64:
+			s := strconv.FormatInt(value, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &value
+		case bool:
+			v.BoolValue = &value
+		default:
+			continue
+		}
+		out = append(out, otlpKeyValue{Key: attr.Key, Value: v})
+	}
+	return out
+}
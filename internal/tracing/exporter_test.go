@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJSONExporter(t *testing.T) {
+	var buf bytes.Buffer
+	exp := NewJSONExporter(&buf, "pipeline-test")
+
+	start := time.Unix(0, 1500)
+	span := SpanData{
+		Name: "pipeline.process",
+		SpanContext: SpanContext{
+			TraceID: TraceID{0xab, 15: 0x01},
+			SpanID:  SpanID{0xcd, 7: 0x02},
+		},
+		Parent:        SpanID{0xef, 7: 0x03},
+		Start:         start,
+		End:           start.Add(time.Microsecond),
+		Attributes:    []Attribute{String("task.tenant", "alpha"), Int("result", 42), Bool("ok", true)},
+		Links:         []SpanContext{{TraceID: TraceID{1}, SpanID: SpanID{2}}},
+		Status:        StatusError,
+		StatusMessage: "boom",
+	}
+	if err := exp.ExportSpan(span); err != nil {
+		t.Fatalf("ExportSpan() error = %v", err)
+	}
+	if err := exp.ExportSpan(span); err != nil {
+		t.Fatalf("ExportSpan() error = %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per span, got %d lines", len(lines))
+	}
+
+	var req struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []struct {
+					Key   string `json:"key"`
+					Value struct {
+						StringValue string `json:"stringValue"`
+					} `json:"value"`
+				} `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID           string `json:"traceId"`
+					SpanID            string `json:"spanId"`
+					ParentSpanID      string `json:"parentSpanId"`
+					Name              string `json:"name"`
+					Kind              int    `json:"kind"`
+					StartTimeUnixNano string `json:"startTimeUnixNano"`
+					EndTimeUnixNano   string `json:"endTimeUnixNano"`
+					Attributes        []struct {
+						Key   string                     `json:"key"`
+						Value map[string]json.RawMessage `json:"value"`
+					} `json:"attributes"`
+					Links []struct {
+						TraceID string `json:"traceId"`
+						SpanID  string `json:"spanId"`
+					} `json:"links"`
+					Status struct {
+						Code    int    `json:"code"`
+						Message string `json:"message"`
+					} `json:"status"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	if err := json.Unmarshal(lines[0], &req); err != nil {
+		t.Fatalf("Failed to decode exported span: %v", err)
+	}
+
+	rs := req.ResourceSpans[0]
+	if attr := rs.Resource.Attributes[0]; attr.Key != "service.name" || attr.Value.StringValue != "pipeline-test" {
+		t.Errorf("Expected service.name=pipeline-test, got %s=%s", attr.Key, attr.Value.StringValue)
+	}
+
+	got := rs.ScopeSpans[0].Spans[0]
+	checks := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"traceId", got.TraceID, "ab000000000000000000000000000001"},
+		{"spanId", got.SpanID, "cd00000000000002"},
+		{"parentSpanId", got.ParentSpanID, "ef00000000000003"},
+		{"name", got.Name, "pipeline.process"},
+		{"startTimeUnixNano", got.StartTimeUnixNano, "1500"},
+		{"endTimeUnixNano", got.EndTimeUnixNano, "2500"},
+		{"link traceId", got.Links[0].TraceID, "01000000000000000000000000000000"},
+		{"status message", got.Status.Message, "boom"},
+		{"int attribute", string(got.Attributes[1].Value["intValue"]), `"42"`},
+		{"bool attribute", string(got.Attributes[2].Value["boolValue"]), `true`},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("Expected %s=%s, got %s", c.name, c.want, c.got)
+		}
+	}
+	if got.Kind != spanKindInternal {
+		t.Errorf("Expected kind %d, got %d", spanKindInternal, got.Kind)
+	}
+	if got.Status.Code != int(StatusError) {
+		t.Errorf("Expected status code %d, got %d", StatusError, got.Status.Code)
+	}
+}
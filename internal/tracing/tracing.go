@@ -0,0 +1,248 @@
+// Package tracing records spans with OpenTelemetry-compatible identifiers and
+// exports them in the OTLP JSON encoding.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// IsValid reports whether the ID is non-zero
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// IsValid reports whether the ID is non-zero
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext identifies a span so it can be used as a parent or a link
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// IsValid reports whether both identifiers are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+type contextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc as the parent for
+// spans started from it
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context carried by ctx, if any
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(contextKey{}).(SpanContext)
+	return sc
+}
+
+// StatusCode is the outcome of a span
+type StatusCode int
+
+const (
+	// StatusUnset is the default status
+	StatusUnset StatusCode = iota
+	// StatusOK marks a span as explicitly successful
+	StatusOK
+	// StatusError marks a span as failed
+	StatusError
+)
+
+// Attribute is a key-value pair attached to a span. Value holds a string,
+// int64, float64 or bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String creates a string attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int creates an integer attribute
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Bool creates a boolean attribute
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanData is the immutable record of an ended span handed to an Exporter
+type SpanData struct {
+	Name          string
+	SpanContext   SpanContext
+	Parent        SpanID
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attribute
+	Links         []SpanContext
+	Status        StatusCode
+	StatusMessage string
+}
+
+// Exporter receives spans as they end
+type Exporter interface {
+	ExportSpan(span SpanData) error
+}
+
+// Tracer starts spans and hands them to an exporter when they end. A nil
+// *Tracer is valid and records nothing.
+type Tracer struct {
+	exporter Exporter
+	onError  func(error)
+}
+
+// NewTracer creates a tracer exporting to exp. onError, if not nil, is called
+// with any export error.
+func NewTracer(exp Exporter, onError func(error)) *Tracer {
+	return &Tracer{exporter: exp, onError: onError}
+}
+
+// Start begins a span named name now. The span is a child of the span
+// context carried by ctx, or the root of a new trace if there is none. The
+// returned context carries the new span.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	return t.StartAt(ctx, name, time.Now())
+}
+
+// StartAt is like Start but uses start as the span start time
+func (t *Tracer) StartAt(ctx context.Context, name string, start time.Time) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	parent := SpanContextFromContext(ctx)
+	s := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:   name,
+			Parent: parent.SpanID,
+			Start:  start,
+		},
+	}
+	s.data.SpanContext.TraceID = parent.TraceID
+	if !parent.IsValid() {
+		s.data.SpanContext.TraceID = newTraceID()
+	}
+	s.data.SpanContext.SpanID = newSpanID()
+
+	return ContextWithSpanContext(ctx, s.data.SpanContext), s
+}
+
+// Span is an operation in progress. A nil *Span is valid and records nothing.
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the identifiers of the span
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// AddLink links the span to another span, typically in a different trace
+func (s *Span) AddLink(sc SpanContext) {
+	if s == nil || !sc.IsValid() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Links = append(s.data.Links, sc)
+}
+
+// RecordError marks the span as failed when err is not nil
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Status = StatusError
+	s.data.StatusMessage = err.Error()
+}
+
+// End completes the span and exports it. Calls after the first are ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if err := s.tracer.exporter.ExportSpan(data); err != nil && s.tracer.onError != nil {
+		s.tracer.onError(err)
+	}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
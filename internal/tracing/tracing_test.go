@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recorder is an exporter keeping spans in memory
+type recorder struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (r *recorder) ExportSpan(span SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = append(r.spans, span)
+	return nil
+}
+
+func TestTracerStart(t *testing.T) {
+	rec := &recorder{}
+	tracer := NewTracer(rec, nil)
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	child.RecordError(errors.New("boom"))
+	child.SetAttributes(String("tenant", "alpha"), Int("value", 3))
+	child.AddLink(SpanContext{})
+	child.End()
+	child.End()
+	root.End()
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(rec.spans))
+	}
+	c, r := rec.spans[0], rec.spans[1]
+
+	if !r.SpanContext.IsValid() {
+		t.Error("Expected root span context to be valid")
+	}
+	if r.Parent.IsValid() {
+		t.Errorf("Expected root span to have no parent, got %s", r.Parent)
+	}
+	if c.SpanContext.TraceID != r.SpanContext.TraceID {
+		t.Error("Expected child to share the root trace ID")
+	}
+	if c.Parent != r.SpanContext.SpanID {
+		t.Errorf("Expected child parent %s, got %s", r.SpanContext.SpanID, c.Parent)
+	}
+	if c.Status != StatusError || c.StatusMessage != "boom" {
+		t.Errorf("Expected error status with message boom, got %d %q", c.Status, c.StatusMessage)
+	}
+	if len(c.Attributes) != 2 {
+		t.Errorf("Expected 2 attributes, got %d", len(c.Attributes))
+	}
+	if len(c.Links) != 0 {
+		t.Errorf("Expected invalid link to be dropped, got %d links", len(c.Links))
+	}
+	if c.End.Before(c.Start) {
+		t.Error("Expected span end after start")
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+
+	ctx := context.Background()
+	got, span := tracer.Start(ctx, "noop")
+	if got != ctx {
+		t.Error("Expected nil tracer to return the context unchanged")
+	}
+	if span != nil {
+		t.Fatal("Expected nil span from nil tracer")
+	}
+
+	// Methods on a nil span must not panic
+	span.SetAttributes(String("k", "v"))
+	span.AddLink(SpanContext{})
+	span.RecordError(errors.New("ignored"))
+	span.End()
+	if span.SpanContext().IsValid() {
+		t.Error("Expected nil span to have an invalid span context")
+	}
+}
+
+func TestSpanContextFromContext(t *testing.T) {
+	if SpanContextFromContext(context.Background()).IsValid() {
+		t.Error("Expected empty context to carry no span context")
+	}
+
+	sc := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}}
+	ctx := ContextWithSpanContext(context.Background(), sc)
+	if got := SpanContextFromContext(ctx); got != sc {
+		t.Errorf("Expected %v, got %v", sc, got)
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import "fmt"
+
+// Control is a control step; a valid step sets exactly one of its fields
+type Control struct {
+	// If branches on the current value
+	If *If `json:"if,omitempty"`
+	// Clamp bounds the current value
+	Clamp *Clamp `json:"clamp,omitempty"`
+	// Exit ends the evaluation of the task with the current value when its
+	// condition holds
+	Exit *Condition `json:"exit,omitempty"`
+}
+
+// Comparison compares the current value of a task with a threshold
+type Comparison int
+
+const (
+	CompareGreater Comparison = iota
+	CompareGreaterOrEqual
+	CompareLess
+	CompareLessOrEqual
+	CompareEqual
+	CompareNotEqual
+	CompareTotalAmount
+)
+
+func (c Comparison) String() string {
+	switch c {
+	case CompareGreater:
+		return ">"
+	case CompareGreaterOrEqual:
+		return ">="
+	case CompareLess:
+		return "<"
+	case CompareLessOrEqual:
+		return "<="
+	case CompareEqual:
+		return "=="
+	case CompareNotEqual:
+		return "!="
+	default:
+		return "unknown"
+	}
+}
+
+// Holds reports whether the comparison holds given cmp, which is -1, 0 or +1
+// as the value is less than, equal to or greater than the threshold
+func (c Comparison) Holds(cmp int) bool {
+	switch c {
+	case CompareGreater:
+		return cmp > 0
+	case CompareGreaterOrEqual:
+		return cmp >= 0
+	case CompareLess:
+		return cmp < 0
+	case CompareLessOrEqual:
+		return cmp <= 0
+	case CompareEqual:
+		return cmp == 0
+	case CompareNotEqual:
+		return cmp != 0
+	default:
+		return false
+	}
+}
+
+// Negate returns the comparison holding exactly when c does not
+func (c Comparison) Negate() Comparison {
+	switch c {
+	case CompareGreater:
+		return CompareLessOrEqual
+	case CompareGreaterOrEqual:
+		return CompareLess
+	case CompareLess:
+		return CompareGreaterOrEqual
+	case CompareLessOrEqual:
+		return CompareGreater
+	case CompareEqual:
+		return CompareNotEqual
+	case CompareNotEqual:
+		return CompareEqual
+	default:
+		return c
+	}
+}
+
+// Condition holds when the current value compares to Value as Compare says
+type Condition struct {
+	Compare Comparison `json:"compare"`
+	Value   int        `json:"value"`
+}
+
+func (c Condition) String() string {
+	return fmt.Sprintf("value %s %d", c.Compare, c.Value)
+}
+
+// If applies the Then operations when its condition holds and the Else
+// operations otherwise
+type If struct {
+	Condition
+	Then []Operation `json:"then"`
+	Else []Operation `json:"else,omitempty"`
+}
+
+// Clamp bounds the current value to [Min, Max]; either bound may be omitted
+type Clamp struct {
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+}
+
+func (c Clamp) String() string {
+	bound := func(b *int) string {
+		if b == nil {
+			return "_"
+		}
+		return fmt.Sprint(*b)
+	}
+	return fmt.Sprintf("clamp [%s, %s]", bound(c.Min), bound(c.Max))
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestComparison(t *testing.T) {
+	for c := CompareGreater; c < CompareTotalAmount; c++ {
+		t.Run(c.String(), func(t *testing.T) {
+			for _, cmp := range []int{-1, 0, 1} {
+				if c.Holds(cmp) == c.Negate().Holds(cmp) {
+					t.Errorf("Expected %s and %s to disagree for %d", c, c.Negate(), cmp)
+				}
+			}
+			if c.Negate().Negate() != c {
+				t.Errorf("Expected double negation to give %s, got %s", c, c.Negate().Negate())
+			}
+		})
+	}
+
+	if CompareTotalAmount.Holds(0) {
+		t.Error("Expected an invalid comparison never to hold")
+	}
+}
+
+func TestControlJSON(t *testing.T) {
+	data := `{"value": 150, "operations": [
+		{"control": {"if": {"compare": 0, "value": 100, "then": [{"operator": 3, "value": 2}]}}},
+		{"control": {"clamp": {"max": 500}}},
+		{"control": {"exit": {"compare": 4, "value": 0}}}
+	]}`
+
+	var task Task
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	limit := 500
+	want := []Operation{
+		{Control: &Control{If: &If{
+			Condition: Condition{Compare: CompareGreater, Value: 100},
+			Then:      []Operation{{Operator: OperatorMultiply, Value: 2}},
+		}}},
+		{Control: &Control{Clamp: &Clamp{Max: &limit}}},
+		{Control: &Control{Exit: &Condition{Compare: CompareEqual, Value: 0}}},
+	}
+	if !reflect.DeepEqual(task.Operations, want) {
+		t.Errorf("Expected %+v, got %+v", want, task.Operations)
+	}
+	for i, op := range task.Operations {
+		if !op.IsControl() {
+			t.Errorf("Expected operation %d to be a control step", i)
+		}
+	}
+	if got := want[1].Control.Clamp.String(); got != "clamp [_, 500]" {
+		t.Errorf("Expected clamp [_, 500], got %s", got)
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Decimal is a fixed-point number equal to Unscaled / 10^Scale
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int
+}
+
+// Pow10 returns 10^n
+func Pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Rescale returns d with the given scale, which must not be below d.Scale
+func (d Decimal) Rescale(scale int) Decimal {
+	if scale <= d.Scale {
+		return d
+	}
+	u := new(big.Int).Mul(d.Unscaled, Pow10(scale-d.Scale))
+	return Decimal{Unscaled: u, Scale: scale}
+}
+
+// String formats the decimal in base 10 with exactly Scale fractional digits
+func (d Decimal) String() string {
+	if d.Unscaled == nil {
+		return "0"
+	}
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	if d.Scale > 0 {
+		if len(digits) <= d.Scale {
+			digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+		}
+		point := len(digits) - d.Scale
+		digits = digits[:point] + "." + digits[point:]
+	}
+	if d.Unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// DecimalResult returns a result holding d, setting Result to its integer
+// part when that fits in an int
+func DecimalResult(d Decimal) Result {
+	r := Result{Decimal: &d}
+	whole := new(big.Int).Quo(d.Unscaled, Pow10(d.Scale))
+	if whole.IsInt64() && whole.Int64() >= math.MinInt && whole.Int64() <= math.MaxInt {
+		r.Result = int(whole.Int64())
+	}
+	return r
+}
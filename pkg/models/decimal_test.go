@@ -0,0 +1,41 @@
+package models
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		name       string
+		decimal    Decimal
+		want       string
+		wantResult int
+	}{
+		{name: "fraction", decimal: Decimal{Unscaled: big.NewInt(1250), Scale: 2}, want: "12.50", wantResult: 12},
+		{name: "leading zeros", decimal: Decimal{Unscaled: big.NewInt(5), Scale: 3}, want: "0.005", wantResult: 0},
+		{name: "negative", decimal: Decimal{Unscaled: big.NewInt(-75), Scale: 2}, want: "-0.75", wantResult: 0},
+		{name: "negative whole part", decimal: Decimal{Unscaled: big.NewInt(-1075), Scale: 2}, want: "-10.75", wantResult: -10},
+		{name: "zero scale", decimal: Decimal{Unscaled: big.NewInt(42)}, want: "42", wantResult: 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.decimal.String(); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+			result := DecimalResult(tt.decimal)
+			if got := result.Exact(); got != tt.want {
+				t.Errorf("Expected exact %s, got %s", tt.want, got)
+			}
+			if result.Result != tt.wantResult {
+				t.Errorf("Expected result %d, got %d", tt.wantResult, result.Result)
+			}
+		})
+	}
+
+	rescaled := Decimal{Unscaled: big.NewInt(125), Scale: 1}.Rescale(3)
+	if got := rescaled.String(); got != "12.500" {
+		t.Errorf("Expected 12.500, got %s", got)
+	}
+}
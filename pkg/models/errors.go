@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode identifies a kind of error. Codes are stable and safe for
+// clients to match on; messages are not.
+type ErrorCode string
+
+// Task errors reported by the validator and the processor
+const (
+	CodeNilOperations      ErrorCode = "nil_operations"
+	CodeEmptyOperations    ErrorCode = "empty_operations"
+	CodeTooManyOperations  ErrorCode = "too_many_operations"
+	CodeInvalidOperator    ErrorCode = "invalid_operator"
+	CodeOperatorNotAllowed ErrorCode = "operator_not_allowed"
+	CodeDivisionByZero     ErrorCode = "division_by_zero"
+	CodeValueOutOfRange    ErrorCode = "value_out_of_range"
+	CodeOperandOutOfRange  ErrorCode = "operand_out_of_range"
+	CodeOverflow           ErrorCode = "overflow"
+	CodePossibleOverflow   ErrorCode = "possible_overflow"
+	CodeNaN                ErrorCode = "nan"
+	CodeInfinity           ErrorCode = "infinity"
+	CodeInvalidControl     ErrorCode = "invalid_control"
+	CodeNestingTooDeep     ErrorCode = "nesting_too_deep"
+)
+
+// Pipeline errors reported when a task is submitted or a stage fails
+const (
+	CodeNotStarted        ErrorCode = "not_started"
+	CodeAlreadyStarted    ErrorCode = "already_started"
+	CodeStopped           ErrorCode = "stopped"
+	CodeIntakePaused      ErrorCode = "intake_paused"
+	CodePaused            ErrorCode = "paused"
+	CodeInvalidPriority   ErrorCode = "invalid_priority"
+	CodeRateLimited       ErrorCode = "rate_limited"
+	CodeTenantRateLimited ErrorCode = "tenant_rate_limited"
+	CodeBufferFull        ErrorCode = "buffer_full"
+	CodePanic             ErrorCode = "panic"
+	// CodeInternal is reported for errors that carry no code
+	CodeInternal ErrorCode = "internal"
+)
+
+// Request errors reported by the ingestion API
+const (
+	CodeInvalidRequest  ErrorCode = "invalid_request"
+	CodeRequestTooLarge ErrorCode = "request_too_large"
+)
+
+// Stages an Error can originate from
+const (
+	StageIntake     = "intake"
+	StageValidator  = "validator"
+	StageProcessor  = "processor"
+	StageAggregator = "aggregator"
+)
+
+// Error is the structured error used across the pipeline. Sentinel errors
+// are Errors holding only a code and a message; the stages wrap them with the
+// task and operation they concern, so errors.Is matches the sentinel.
+type Error struct {
+	// Code identifies the kind of error
+	Code ErrorCode
+	// Stage is the stage that reported the error, if known
+	Stage string
+	// TaskID is the ID of the task concerned, if any
+	TaskID string
+	// Index is the position of the offending operation, or -1
+	Index int
+	// Err is the underlying error
+	Err error
+}
+
+// NewError returns a sentinel error with the given code and message
+func NewError(code ErrorCode, msg string) *Error {
+	return &Error{Code: code, Index: -1, Err: errors.New(msg)}
+}
+
+// WrapError attaches a stage, task ID and operation index to err, keeping
+// its code. It returns nil if err is nil.
+func WrapError(err error, stage, taskID string, index int) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: CodeOf(err), Stage: stage, TaskID: taskID, Index: index, Err: err}
+}
+
+func (e *Error) Error() string {
+	msg := e.Err.Error()
+	if e.Index >= 0 {
+		msg = fmt.Sprintf("operation %d: %s", e.Index, msg)
+	}
+	if e.TaskID != "" {
+		msg = fmt.Sprintf("task %s: %s", e.TaskID, msg)
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf returns the code of the first Error in err's chain, CodeInternal if
+// there is none, or an empty code for a nil error
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeInternal
+}
+
+// StageOf returns the stage of the first Error in err's chain, or an empty
+// stage if there is none or it does not know its stage
+func StageOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Stage
+	}
+	return ""
+}
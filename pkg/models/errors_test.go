@@ -0,0 +1,85 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	sentinel := NewError(CodeDivisionByZero, "division by zero")
+
+	tests := []struct {
+		name      string
+		err       error
+		wantMsg   string
+		wantCode  ErrorCode
+		wantStage string
+	}{
+		{
+			name:     "sentinel",
+			err:      sentinel,
+			wantMsg:  "division by zero",
+			wantCode: CodeDivisionByZero,
+		},
+		{
+			name:      "wrapped with operation",
+			err:       WrapError(sentinel, StageProcessor, "", 2),
+			wantMsg:   "operation 2: division by zero",
+			wantCode:  CodeDivisionByZero,
+			wantStage: StageProcessor,
+		},
+		{
+			name:      "wrapped with task",
+			err:       WrapError(sentinel, StageProcessor, "t-1", 0),
+			wantMsg:   "task t-1: operation 0: division by zero",
+			wantCode:  CodeDivisionByZero,
+			wantStage: StageProcessor,
+		},
+		{
+			name:      "wrapped by fmt",
+			err:       fmt.Errorf("context: %w", WrapError(sentinel, StageIntake, "t-2", -1)),
+			wantMsg:   "context: task t-2: division by zero",
+			wantCode:  CodeDivisionByZero,
+			wantStage: StageIntake,
+		},
+		{
+			name:     "uncoded error",
+			err:      errors.New("boom"),
+			wantMsg:  "boom",
+			wantCode: CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.wantMsg {
+				t.Errorf("Expected message %q, got %q", tt.wantMsg, got)
+			}
+			if got := CodeOf(tt.err); got != tt.wantCode {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, got)
+			}
+			if got := StageOf(tt.err); got != tt.wantStage {
+				t.Errorf("Expected stage %q, got %q", tt.wantStage, got)
+			}
+			if tt.wantCode != CodeInternal && !errors.Is(tt.err, sentinel) {
+				t.Errorf("Expected errors.Is to match the sentinel for %v", tt.err)
+			}
+		})
+	}
+
+	if WrapError(nil, StageProcessor, "t", 0) != nil {
+		t.Error("Expected WrapError(nil) to return nil")
+	}
+	if got := CodeOf(nil); got != "" {
+		t.Errorf("Expected empty code for nil, got %s", got)
+	}
+
+	var e *Error
+	if !errors.As(WrapError(sentinel, StageValidator, "t-3", 1), &e) {
+		t.Fatal("Expected errors.As to find *Error")
+	}
+	if e.Stage != StageValidator || e.TaskID != "t-3" || e.Index != 1 {
+		t.Errorf("Expected validator/t-3/1, got %s/%s/%d", e.Stage, e.TaskID, e.Index)
+	}
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidExpression is returned by ParseExpression for malformed input
+var ErrInvalidExpression = errors.New("invalid expression")
+
+// Explanation records the intermediate values of a task's evaluation
+type Explanation struct {
+	// Value is the initial value of the task
+	Value int `json:"value"`
+	// Steps lists the operations applied in order; when evaluation failed
+	// it stops before the failing operation
+	Steps []ExplainStep `json:"steps"`
+}
+
+// ExplainStep is one applied operation and the value it produced
+type ExplainStep struct {
+	// Index is the position of the top-level operation the step belongs to
+	Index    int      `json:"index"`
+	Operator Operator `json:"operator"`
+	Operand  int      `json:"operand"`
+	Result   int      `json:"result"`
+	// Control describes a control step, such as "if value > 100: then";
+	// Operator and Operand are unset for those
+	Control string `json:"control,omitempty"`
+	// Depth is the number of if steps the operation is nested in
+	Depth int `json:"depth,omitempty"`
+}
+
+// String renders the explanation one line per operation, such as "+ 5 = 15",
+// indenting the operations of if branches
+func (e Explanation) String() string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(e.Value))
+	for _, s := range e.Steps {
+		b.WriteString("\n" + strings.Repeat("  ", s.Depth))
+		if s.Control != "" {
+			b.WriteString(s.Control)
+			continue
+		}
+		fmt.Fprintf(&b, "%s %d = %d", s.Operator.Symbol(), s.Operand, s.Result)
+	}
+	return b.String()
+}
+
+// Symbol returns the arithmetic symbol of the operator, as accepted by
+// ParseExpression
+func (o Operator) Symbol() string {
+	switch o {
+	case OperatorPlus:
+		return "+"
+	case OperatorMinus:
+		return "-"
+	case OperatorDivide:
+		return "/"
+	case OperatorMultiply:
+		return "*"
+	default:
+		return "?"
+	}
+}
+
+// ParseExpression parses an expression such as "10 + 5 * 2" into a task.
+// Operations apply from left to right, as in a task, so the example yields
+// 30; there is no operator precedence.
+func ParseExpression(s string) (Task, error) {
+	fields := tokenize(s)
+	if len(fields) == 0 || len(fields)%2 == 0 {
+		return Task{}, fmt.Errorf("%w: expected a value followed by operator and operand pairs", ErrInvalidExpression)
+	}
+
+	value, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Task{}, fmt.Errorf("%w: bad value %q", ErrInvalidExpression, fields[0])
+	}
+	task := Task{Value: value, Operations: make([]Operation, 0, len(fields)/2)}
+	for i := 1; i < len(fields); i += 2 {
+		op, ok := operatorBySymbol(fields[i])
+		if !ok {
+			return Task{}, fmt.Errorf("%w: bad operator %q", ErrInvalidExpression, fields[i])
+		}
+		operand, err := strconv.Atoi(fields[i+1])
+		if err != nil {
+			return Task{}, fmt.Errorf("%w: bad operand %q", ErrInvalidExpression, fields[i+1])
+		}
+		task.Operations = append(task.Operations, Operation{Operator: op, Value: operand})
+	}
+	return task, nil
+}
+
+func operatorBySymbol(s string) (Operator, bool) {
+	for o := OperatorPlus; o < OperatorTotalAmount; o++ {
+		if o.Symbol() == s {
+			return o, true
+		}
+	}
+	return 0, false
+}
+
+// tokenize splits s into alternating numbers and operator symbols. A sign
+// directly in front of a digit belongs to the number when a number is
+// expected, so "2*-3" splits into "2", "*" and "-3".
+func tokenize(s string) []string {
+	var fields []string
+	wantNumber := true
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case wantNumber:
+			j := i
+			if c == '-' || c == '+' {
+				j++
+			}
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			if j == i {
+				// Not a number; let the caller report it
+				j = i + 1
+			}
+			fields = append(fields, s[i:j])
+			i, wantNumber = j, false
+		default:
+			fields = append(fields, s[i:i+1])
+			i, wantNumber = i+1, true
+		}
+	}
+	return fields
+}
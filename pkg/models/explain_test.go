@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    Task
+		wantErr bool
+	}{
+		{
+			name: "spaced",
+			expr: "10 + 5 * 2",
+			want: Task{Value: 10, Operations: []Operation{{Operator: OperatorPlus, Value: 5}, {Operator: OperatorMultiply, Value: 2}}},
+		},
+		{
+			name: "compact with signs",
+			expr: "-4/2-+3*-1",
+			want: Task{Value: -4, Operations: []Operation{
+				{Operator: OperatorDivide, Value: 2},
+				{Operator: OperatorMinus, Value: 3},
+				{Operator: OperatorMultiply, Value: -1},
+			}},
+		},
+		{name: "value only", expr: "7", want: Task{Value: 7, Operations: []Operation{}}},
+		{name: "empty", expr: " ", wantErr: true},
+		{name: "missing operand", expr: "10 +", wantErr: true},
+		{name: "unknown operator", expr: "10 % 3", wantErr: true},
+		{name: "bad value", expr: "x + 1", wantErr: true},
+		{name: "operand out of range", expr: "1 + 99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExpression(tt.expr)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidExpression) {
+					t.Errorf("Expected ErrInvalidExpression, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseExpression(%q) error = %v", tt.expr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExplanationString(t *testing.T) {
+	e := Explanation{Value: 10, Steps: []ExplainStep{
+		{Index: 0, Operator: OperatorPlus, Operand: 5, Result: 15},
+		{Index: 1, Operator: OperatorDivide, Operand: -2, Result: -7},
+	}}
+	want := "10\n+ 5 = 15\n/ -2 = -7"
+	if got := e.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -1,5 +1,13 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
 type Operator int
 
 const (
@@ -10,17 +18,148 @@ const (
 	OperatorTotalAmount
 )
 
+// ErrUnknownOperator is returned by ParseOperator for an unrecognised name
+var ErrUnknownOperator = errors.New("unknown operator")
+
+func (o Operator) String() string {
+	switch o {
+	case OperatorPlus:
+		return "plus"
+	case OperatorMinus:
+		return "minus"
+	case OperatorDivide:
+		return "divide"
+	case OperatorMultiply:
+		return "multiply"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseOperator returns the operator named s, as returned by Operator.String
+func ParseOperator(s string) (Operator, error) {
+	for o := OperatorPlus; o < OperatorTotalAmount; o++ {
+		if o.String() == s {
+			return o, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownOperator, s)
+}
+
+// Priority selects the scheduling lane of a task
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+	PriorityLow
+	PriorityTotalAmount
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
 type Operation struct {
-	Operator Operator
-	Value    int
+	Operator Operator `json:"operator"`
+	Value    int      `json:"value"`
+	// Float is the operand in float mode, where it replaces Value when set
+	Float *float64 `json:"float,omitempty"`
+	// Control makes the operation a control step, whose Operator and Value
+	// are ignored
+	Control *Control `json:"control,omitempty"`
+}
+
+// IsControl reports whether the operation is a control step
+func (o Operation) IsControl() bool {
+	return o.Control != nil
+}
+
+// FloatValue returns the operand used in float mode
+func (o Operation) FloatValue() float64 {
+	if o.Float != nil {
+		return *o.Float
+	}
+	return float64(o.Value)
 }
 
 type Task struct {
-	Value      int
-	Operations []Operation
+	// ID identifies the task in errors; it is optional
+	ID         string      `json:"id,omitempty"`
+	Value      int         `json:"value"`
+	Operations []Operation `json:"operations"`
+	// Float is the initial value in float mode, where it replaces Value when set
+	Float *float64 `json:"float,omitempty"`
+	// Tenant identifies the producer of the task for per-tenant rate limiting
+	Tenant string `json:"tenant,omitempty"`
+	// Priority selects the lane the task is queued in; the zero value is PriorityNormal
+	Priority Priority `json:"priority,omitempty"`
+}
+
+// FloatValue returns the initial value used in float mode
+func (t Task) FloatValue() float64 {
+	if t.Float != nil {
+		return *t.Float
+	}
+	return float64(t.Value)
 }
 
 type Result struct {
+	// Result is the value of the result; in arbitrary precision mode it is
+	// only set when the value fits in an int, and in decimal and float mode
+	// it holds the integer part
 	Result int
-	Error  error
+	// Big holds the exact value of a result computed in arbitrary precision mode
+	Big *big.Int
+	// Decimal holds the exact value of a result computed in decimal mode
+	Decimal *Decimal
+	// Float holds the value of a result computed in float mode
+	Float *float64
+	Error error
+	// Explanation records the intermediate values of the evaluation when
+	// explaining was requested
+	Explanation *Explanation
+}
+
+// Exact returns the exact value of the result in base 10
+func (r Result) Exact() string {
+	switch {
+	case r.Float != nil:
+		return strconv.FormatFloat(*r.Float, 'g', -1, 64)
+	case r.Decimal != nil:
+		return r.Decimal.String()
+	case r.Big != nil:
+		return r.Big.String()
+	default:
+		return strconv.Itoa(r.Result)
+	}
+}
+
+// FloatResult returns a result holding f, setting Result to its integer part
+// when that fits in an int
+func FloatResult(f float64) Result {
+	r := Result{Float: &f}
+	if whole := math.Trunc(f); whole >= math.MinInt && whole < math.MaxInt {
+		r.Result = int(whole)
+	}
+	return r
+}
+
+// BigResult returns a result holding v, setting Result as well when v fits
+// in an int
+func BigResult(v *big.Int) Result {
+	r := Result{Big: v}
+	if v.IsInt64() && v.Int64() >= math.MinInt && v.Int64() <= math.MaxInt {
+		r.Result = int(v.Int64())
+	}
+	return r
 }
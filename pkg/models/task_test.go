@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParseOperator(t *testing.T) {
+	for op := OperatorPlus; op < OperatorTotalAmount; op++ {
+		t.Run(op.String(), func(t *testing.T) {
+			got, err := ParseOperator(op.String())
+			if err != nil {
+				t.Fatalf("ParseOperator(%q) error = %v", op.String(), err)
+			}
+			if got != op {
+				t.Errorf("ParseOperator(%q) = %v, want %v", op.String(), got, op)
+			}
+		})
+	}
+
+	if _, err := ParseOperator("modulo"); !errors.Is(err, ErrUnknownOperator) {
+		t.Errorf("Expected ErrUnknownOperator, got %v", err)
+	}
+	if got := OperatorTotalAmount.String(); got != "unknown" {
+		t.Errorf("Expected unknown, got %s", got)
+	}
+}
+
+func TestResultExact(t *testing.T) {
+	large, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+
+	tests := []struct {
+		name       string
+		result     Result
+		wantExact  string
+		wantResult int
+	}{
+		{name: "native", result: Result{Result: 42}, wantExact: "42", wantResult: 42},
+		{name: "big within int", result: BigResult(big.NewInt(-7)), wantExact: "-7", wantResult: -7},
+		{name: "big beyond int", result: BigResult(large), wantExact: large.String(), wantResult: 0},
+		{name: "float", result: FloatResult(-2.75), wantExact: "-2.75", wantResult: -2},
+		{name: "float beyond int", result: FloatResult(1e300), wantExact: "1e+300", wantResult: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Exact(); got != tt.wantExact {
+				t.Errorf("Expected exact %s, got %s", tt.wantExact, got)
+			}
+			if tt.result.Result != tt.wantResult {
+				t.Errorf("Expected result %d, got %d", tt.wantResult, tt.result.Result)
+			}
+		})
+	}
+}
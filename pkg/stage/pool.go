@@ -0,0 +1,248 @@
+package stage
+
+import (
+	"context"
+	"sync"
+)
+
+// workerExit describes why a pool worker returned
+type workerExit int
+
+const (
+	// exitDone means the input was exhausted or the context was cancelled
+	exitDone workerExit = iota
+	// exitQuit means the worker was asked to stop by a shrink
+	exitQuit
+	// exitRestart means the worker failed a value and must be replaced
+	exitRestart
+)
+
+// Pool is a stage handling values on a set of worker goroutines that can be
+// resized and paused while it runs. Values may be emitted out of order.
+type Pool[In, Out any] struct {
+	fn      func(context.Context, In) (Out, error)
+	onError ErrorHandler[In, Out]
+	opts    options
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	size    int
+	quits   []chan struct{}
+	running bool
+	closed  bool
+	paused  bool
+	resumed chan struct{}
+
+	ctx context.Context
+	in  <-chan In
+	out chan Out
+}
+
+// NewPool creates a pool of the given number of workers applying fn to every
+// input value. When fn returns an error or panics, onError decides what is
+// emitted instead; a nil onError drops the value. WithWorkers is ignored.
+func NewPool[In, Out any](workers int, fn func(context.Context, In) (Out, error), onError ErrorHandler[In, Out], opts ...Option) *Pool[In, Out] {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pool[In, Out]{
+		fn:      fn,
+		onError: onError,
+		opts:    newOptions(opts),
+		size:    workers,
+	}
+}
+
+// Run starts the workers reading from in and returns their output. Run makes
+// a Pool usable as a Stage and may only be called once.
+func (p *Pool[In, Out]) Run(ctx context.Context, in <-chan In) <-chan Out {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		panic("stage: pool already running")
+	}
+	p.running = true
+	p.ctx = ctx
+	p.in = in
+	p.out = make(chan Out, p.opts.buffer)
+
+	for i := 0; i < p.size; i++ {
+		p.spawnLocked(make(chan struct{}))
+	}
+
+	out := p.out
+	go func() {
+		p.wg.Wait()
+		finish(out, p.opts)
+	}()
+	return out
+}
+
+// Resize grows or shrinks the pool to n workers, at least one. Shrinking
+// signals idle workers to return; a worker handling a value finishes it
+// first. Before Run it sets the number of workers to start; once the input is
+// exhausted it is a no-op.
+func (p *Pool[In, Out]) Resize(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.running {
+		p.size = n
+		return
+	}
+	if p.closed {
+		return
+	}
+
+	for len(p.quits) < n {
+		p.spawnLocked(make(chan struct{}))
+	}
+	for len(p.quits) > n {
+		last := len(p.quits) - 1
+		close(p.quits[last])
+		p.quits = p.quits[:last]
+	}
+}
+
+// Size returns the number of running workers
+func (p *Pool[In, Out]) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.quits)
+}
+
+// Pause stops the workers from taking values until Resume is called; values
+// being handled finish
+func (p *Pool[In, Out]) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paused {
+		p.paused = true
+		p.resumed = make(chan struct{})
+	}
+}
+
+// Resume lets the workers take values again after Pause
+func (p *Pool[In, Out]) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.paused {
+		p.paused = false
+		close(p.resumed)
+	}
+}
+
+// Paused reports whether the pool is paused
+func (p *Pool[In, Out]) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// gate returns a channel that is closed when the pool resumes, or nil if the
+// pool is not paused
+func (p *Pool[In, Out]) gate() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paused {
+		return nil
+	}
+	return p.resumed
+}
+
+func (p *Pool[In, Out]) spawnLocked(quit chan struct{}) {
+	p.quits = append(p.quits, quit)
+	p.wg.Add(1)
+	go p.loop(quit)
+}
+
+func (p *Pool[In, Out]) loop(quit chan struct{}) {
+	defer p.wg.Done()
+
+	reason := p.work(quit)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch reason {
+	case exitRestart:
+		if !p.closed {
+			// Reuse the slot so a later shrink can still stop the replacement
+			p.wg.Add(1)
+			go p.loop(quit)
+			return
+		}
+		p.removeLocked(quit)
+	case exitQuit:
+		// Already removed by Resize
+	default:
+		p.closed = true
+		p.removeLocked(quit)
+	}
+}
+
+func (p *Pool[In, Out]) removeLocked(quit chan struct{}) {
+	for i, q := range p.quits {
+		if q == quit {
+			p.quits = append(p.quits[:i], p.quits[i+1:]...)
+			return
+		}
+	}
+}
+
+// work handles values until the input is closed, the context is cancelled
+// or quit is closed
+func (p *Pool[In, Out]) work(quit <-chan struct{}) workerExit {
+	ctx := p.ctx
+	for {
+		// Wait while the pool is paused
+		if gate := p.gate(); gate != nil {
+			select {
+			case <-ctx.Done():
+				return exitDone
+			case <-quit:
+				return exitQuit
+			case <-gate:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return exitDone
+		case <-quit:
+			return exitQuit
+		case v, ok := <-p.in:
+			if !ok {
+				return exitDone
+			}
+			// Hold a value taken just as the pool paused until it resumes
+			if gate := p.gate(); gate != nil {
+				select {
+				case <-ctx.Done():
+					return exitDone
+				case <-gate:
+				}
+			}
+
+			res, err := call(ctx, p.fn, v)
+			emit := err == nil
+			if err != nil && p.onError != nil {
+				res, emit = p.onError(ctx, v, err)
+			}
+			if emit && !send(ctx, p.out, res) {
+				return exitDone
+			}
+			if err != nil && p.opts.restart {
+				return exitRestart
+			}
+		}
+	}
+}
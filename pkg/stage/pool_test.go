@@ -0,0 +1,126 @@
+package stage
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitForSize[In, Out any](t *testing.T, p *Pool[In, Out], want int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Size() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d workers, got %d", want, p.Size())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPool(t *testing.T) {
+	square := func(_ context.Context, v int) (int, error) { return v * v, nil }
+
+	t.Run("processes every value", func(t *testing.T) {
+		ctx := context.Background()
+
+		p := NewPool(3, square, nil)
+		got := Collect(ctx, p.Run(ctx, Source(ctx, 1, 2, 3, 4)))
+		sort.Ints(got)
+
+		if len(got) != 4 || got[0] != 1 || got[3] != 16 {
+			t.Errorf("Expected [1 4 9 16], got %v", got)
+		}
+		if size := p.Size(); size != 0 {
+			t.Errorf("Expected no workers after the input closed, got %d", size)
+		}
+	})
+
+	t.Run("resizes before and after run", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := NewPool(2, square, nil)
+		p.Resize(4)
+		if size := p.Size(); size != 0 {
+			t.Errorf("Expected no workers before Run, got %d", size)
+		}
+
+		in := make(chan int)
+		p.Run(ctx, in)
+		waitForSize(t, p, 4)
+
+		p.Resize(7)
+		waitForSize(t, p, 7)
+		p.Resize(1)
+		waitForSize(t, p, 1)
+	})
+
+	t.Run("holds values while paused", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var handled atomic.Int64
+		p := NewPool(2, func(_ context.Context, v int) (int, error) {
+			handled.Add(1)
+			return v, nil
+		}, nil, WithBuffer(10))
+
+		in := make(chan int, 10)
+		out := p.Run(ctx, in)
+
+		p.Pause()
+		if !p.Paused() {
+			t.Error("Expected pool to be paused")
+		}
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+		time.Sleep(50 * time.Millisecond)
+		if n := handled.Load(); n != 0 {
+			t.Errorf("Expected no values handled while paused, got %d", n)
+		}
+
+		p.Resume()
+		close(in)
+		if got := Collect(ctx, out); len(got) != 5 {
+			t.Errorf("Expected 5 values after resume, got %v", got)
+		}
+	})
+
+	t.Run("restarts workers after errors", func(t *testing.T) {
+		ctx := context.Background()
+
+		p := NewPool(1, func(_ context.Context, v int) (int, error) {
+			if v < 0 {
+				panic("negative value")
+			}
+			return v, nil
+		}, func(_ context.Context, v int, err error) (int, bool) {
+			return 0, true
+		}, RestartOnError())
+
+		got := Collect(ctx, p.Run(ctx, Source(ctx, 1, -1, 2)))
+		if len(got) != 3 || got[0] != 1 || got[1] != 0 || got[2] != 2 {
+			t.Errorf("Expected [1 0 2], got %v", got)
+		}
+	})
+
+	t.Run("stops on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		closed := make(chan struct{})
+		p := NewPool(2, square, nil, OnClose(func() { close(closed) }))
+		p.Run(ctx, make(chan int))
+		p.Pause()
+
+		cancel()
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for pool to stop after cancellation")
+		}
+	})
+}
@@ -0,0 +1,195 @@
+// Package stage provides typed, composable pipeline stages. A stage reads
+// values from an input channel and writes results to an output channel it
+// owns, closing the output once the input is exhausted or the context is
+// done, so stages can be chained, fanned out and merged safely.
+package stage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Stage transforms a stream of In values into a stream of Out values. A stage
+// closes its output once it has handled every value of a closed input, or as
+// soon as ctx is done.
+type Stage[In, Out any] func(ctx context.Context, in <-chan In) <-chan Out
+
+// ErrorHandler decides what a stage emits for a value it failed to handle.
+// It returns the value to emit in its place, or false to drop it.
+type ErrorHandler[In, Out any] func(ctx context.Context, in In, err error) (Out, bool)
+
+// ErrPanic matches every PanicError via errors.Is
+var ErrPanic = errors.New("stage panicked")
+
+// PanicError is passed to an ErrorHandler when a stage function panics
+type PanicError struct {
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack is the stack trace captured at the point of recovery
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("stage panicked: %v", e.Value)
+}
+
+// Is reports whether target is ErrPanic
+func (e *PanicError) Is(target error) bool {
+	return target == ErrPanic
+}
+
+// Option configures a stage
+type Option func(*options)
+
+type options struct {
+	buffer  int
+	workers int
+	restart bool
+	onClose []func()
+}
+
+// WithBuffer sets the capacity of the stage's output channel; the default is
+// unbuffered
+func WithBuffer(n int) Option {
+	return func(o *options) {
+		o.buffer = n
+	}
+}
+
+// WithWorkers sets the number of goroutines handling values concurrently;
+// values may then be emitted out of order. The default is one.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// OnClose registers fn to run once the stage has stopped and closed its output
+func OnClose(fn func()) Option {
+	return func(o *options) {
+		o.onClose = append(o.onClose, fn)
+	}
+}
+
+// RestartOnError makes a Pool replace a worker goroutine with a fresh one
+// after it fails to handle a value. Other stages ignore it.
+func RestartOnError() Option {
+	return func(o *options) {
+		o.restart = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{workers: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.workers < 1 {
+		o.workers = 1
+	}
+	if o.buffer < 0 {
+		o.buffer = 0
+	}
+	return o
+}
+
+// Chain connects the output of first to the input of second
+func Chain[A, B, C any](first Stage[A, B], second Stage[B, C]) Stage[A, C] {
+	return func(ctx context.Context, in <-chan A) <-chan C {
+		return second(ctx, first(ctx, in))
+	}
+}
+
+// FanOut runs n copies of s reading from the same input and merges their
+// outputs into one. Values may be emitted out of order.
+func FanOut[In, Out any](n int, s Stage[In, Out]) Stage[In, Out] {
+	if n < 1 {
+		n = 1
+	}
+	return func(ctx context.Context, in <-chan In) <-chan Out {
+		outs := make([]<-chan Out, n)
+		for i := range outs {
+			outs[i] = s(ctx, in)
+		}
+		return Merge(ctx, outs...)
+	}
+}
+
+// Merge forwards the values of every input to a single output, which is
+// closed once every input is closed or ctx is done
+func Merge[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		go func(in <-chan T) {
+			defer wg.Done()
+			for v := range in {
+				if !send(ctx, out, v) {
+					return
+				}
+			}
+		}(in)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Source emits values in order and closes the returned channel, stopping
+// early if ctx is done
+func Source[T any](ctx context.Context, values ...T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, v := range values {
+			if !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Collect reads in until it is closed or ctx is done and returns the values read
+func Collect[T any](ctx context.Context, in <-chan T) []T {
+	var values []T
+	for {
+		select {
+		case <-ctx.Done():
+			return values
+		case v, ok := <-in:
+			if !ok {
+				return values
+			}
+			values = append(values, v)
+		}
+	}
+}
+
+// send writes v to out, returning false if ctx is done first
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// call runs fn, converting a panic inside it into a PanicError
+func call[In, Out any](ctx context.Context, fn func(context.Context, In) (Out, error), v In) (out Out, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx, v)
+}
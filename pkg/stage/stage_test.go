@@ -0,0 +1,96 @@
+package stage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func double(_ context.Context, v int) int { return v * 2 }
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+
+	s := Chain(Map(double, nil), Map(func(_ context.Context, v int) int { return v + 1 }, nil))
+	got := Collect(ctx, s(ctx, Source(ctx, 1, 2, 3)))
+
+	want := []int{3, 5, 7}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	ctx := context.Background()
+
+	values := make([]int, 100)
+	for i := range values {
+		values[i] = i
+	}
+	s := FanOut(4, Map(double, nil))
+	got := Collect(ctx, s(ctx, Source(ctx, values...)))
+	sort.Ints(got)
+
+	if len(got) != len(values) {
+		t.Fatalf("Expected %d values, got %d", len(values), len(got))
+	}
+	for i, v := range got {
+		if v != i*2 {
+			t.Fatalf("Expected %d at %d, got %d", i*2, i, v)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+
+	got := Collect(ctx, Merge(ctx, Source(ctx, 1, 2), Source(ctx, 3), Source[int](ctx)))
+	sort.Ints(got)
+
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("Expected [1 2 3], got %v", got)
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// An input that is never closed
+	in := make(chan int)
+	closed := make(chan struct{})
+	out := Map(double, nil, OnClose(func() { close(closed) }))(ctx, in)
+
+	in <- 1
+	if v := <-out; v != 2 {
+		t.Errorf("Expected 2, got %d", v)
+	}
+
+	cancel()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for stage to stop after cancellation")
+	}
+	if _, ok := <-out; ok {
+		t.Error("Expected output to be closed")
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	var err error = &PanicError{Value: "boom"}
+
+	if !errors.Is(err, ErrPanic) {
+		t.Error("Expected PanicError to match ErrPanic")
+	}
+	if got := err.Error(); got != "stage panicked: boom" {
+		t.Errorf("Expected message %q, got %q", "stage panicked: boom", got)
+	}
+}
@@ -0,0 +1,153 @@
+package stage
+
+import (
+	"context"
+	"sync"
+)
+
+// Map returns a stage emitting fn applied to every input value. When fn
+// panics, onError decides what is emitted instead; a nil onError raises the
+// panic again as a *PanicError. Use TryMap when fn can fail.
+func Map[In, Out any](fn func(context.Context, In) Out, onError ErrorHandler[In, Out], opts ...Option) Stage[In, Out] {
+	return TryMap(func(ctx context.Context, v In) (Out, error) {
+		return fn(ctx, v), nil
+	}, orRepanic(onError), opts...)
+}
+
+// TryMap returns a stage emitting fn applied to every input value. When fn
+// returns an error or panics, onError decides what is emitted instead; a nil
+// onError drops the value. Panics are reported as a *PanicError.
+func TryMap[In, Out any](fn func(context.Context, In) (Out, error), onError ErrorHandler[In, Out], opts ...Option) Stage[In, Out] {
+	o := newOptions(opts)
+	return func(ctx context.Context, in <-chan In) <-chan Out {
+		return run(ctx, in, o, func(v In, out chan<- Out) bool {
+			res, err := call(ctx, fn, v)
+			if err != nil {
+				if onError == nil {
+					return true
+				}
+				var ok bool
+				if res, ok = onError(ctx, v, err); !ok {
+					return true
+				}
+			}
+			return send(ctx, out, res)
+		})
+	}
+}
+
+// Filter returns a stage emitting the input values for which keep returns
+// true. When keep panics, onError decides what is emitted instead; a nil
+// onError raises the panic again as a *PanicError.
+func Filter[T any](keep func(T) bool, onError ErrorHandler[T, T], opts ...Option) Stage[T, T] {
+	o := newOptions(opts)
+	onError = orRepanic(onError)
+	check := func(_ context.Context, v T) (bool, error) {
+		return keep(v), nil
+	}
+	return func(ctx context.Context, in <-chan T) <-chan T {
+		return run(ctx, in, o, func(v T, out chan<- T) bool {
+			ok, err := call(ctx, check, v)
+			if err != nil {
+				var res T
+				if res, ok = onError(ctx, v, err); !ok {
+					return true
+				}
+				return send(ctx, out, res)
+			}
+			if !ok {
+				return true
+			}
+			return send(ctx, out, v)
+		})
+	}
+}
+
+// orRepanic returns onError, or a handler panicking with the error it is
+// given when onError is nil, so that a panic is never dropped silently
+func orRepanic[In, Out any](onError ErrorHandler[In, Out]) ErrorHandler[In, Out] {
+	if onError != nil {
+		return onError
+	}
+	return func(_ context.Context, _ In, err error) (Out, bool) {
+		panic(err)
+	}
+}
+
+// Batch returns a stage grouping input values into slices of size values. A
+// final, smaller batch is emitted when the input closes; values still
+// buffered when ctx is done are dropped. A size below one is treated as one.
+// Batch always runs on a single goroutine.
+func Batch[T any](size int, opts ...Option) Stage[T, []T] {
+	if size < 1 {
+		size = 1
+	}
+	o := newOptions(opts)
+	return func(ctx context.Context, in <-chan T) <-chan []T {
+		out := make(chan []T, o.buffer)
+		go func() {
+			defer finish(out, o)
+
+			batch := make([]T, 0, size)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case v, ok := <-in:
+					if !ok {
+						if len(batch) > 0 {
+							send(ctx, out, batch)
+						}
+						return
+					}
+					batch = append(batch, v)
+					if len(batch) == size {
+						if !send(ctx, out, batch) {
+							return
+						}
+						batch = make([]T, 0, size)
+					}
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// run starts the configured number of goroutines calling handle for each
+// input value until in is closed, ctx is done or handle returns false
+func run[In, Out any](ctx context.Context, in <-chan In, o options, handle func(In, chan<- Out) bool) <-chan Out {
+	out := make(chan Out, o.buffer)
+
+	var wg sync.WaitGroup
+	wg.Add(o.workers)
+	for i := 0; i < o.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case v, ok := <-in:
+					if !ok || !handle(v, out) {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		finish(out, o)
+	}()
+	return out
+}
+
+// finish closes a stage's output and runs its OnClose hooks
+func finish[T any](out chan T, o options) {
+	close(out)
+	for _, fn := range o.onClose {
+		fn()
+	}
+}
@@ -0,0 +1,179 @@
+package stage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestTryMap(t *testing.T) {
+	errOdd := errors.New("odd value")
+	half := func(_ context.Context, v int) (int, error) {
+		if v < 0 {
+			panic("negative value")
+		}
+		if v%2 != 0 {
+			return 0, errOdd
+		}
+		return v / 2, nil
+	}
+
+	tests := []struct {
+		name    string
+		onError ErrorHandler[int, int]
+		want    []int
+	}{
+		{
+			name: "drops failures without a handler",
+			want: []int{1, 2},
+		},
+		{
+			name: "emits replacement values",
+			onError: func(_ context.Context, v int, err error) (int, bool) {
+				if errors.Is(err, ErrPanic) {
+					return -100, true
+				}
+				return v * 100, true
+			},
+			want: []int{-100, 1, 2, 300},
+		},
+		{
+			name: "drops values the handler rejects",
+			onError: func(_ context.Context, _ int, err error) (int, bool) {
+				return 0, !errors.Is(err, errOdd)
+			},
+			want: []int{0, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			s := TryMap(half, tt.onError, WithWorkers(2), WithBuffer(4))
+			got := Collect(ctx, s(ctx, Source(ctx, 2, 3, -1, 4)))
+			sort.Ints(got)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMap(t *testing.T) {
+	ctx := context.Background()
+
+	inverse := func(_ context.Context, v int) int { return 100 / v }
+	onPanic := func(_ context.Context, _ int, err error) (int, bool) {
+		if !errors.Is(err, ErrPanic) {
+			t.Errorf("Expected ErrPanic, got %v", err)
+		}
+		return -1, true
+	}
+	got := Collect(ctx, Map(inverse, onPanic)(ctx, Source(ctx, 0, 4, 5)))
+
+	if len(got) != 3 || got[0] != -1 || got[1] != 25 || got[2] != 20 {
+		t.Errorf("Expected [-1 25 20], got %v", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	even := func(v int) bool {
+		if v == 0 {
+			panic("zero")
+		}
+		return v%2 == 0
+	}
+
+	tests := []struct {
+		name    string
+		onError ErrorHandler[int, int]
+		want    []int
+	}{
+		{
+			name: "emits replacement values",
+			onError: func(_ context.Context, v int, err error) (int, bool) {
+				return -1, errors.Is(err, ErrPanic)
+			},
+			want: []int{-1, 2, 4},
+		},
+		{
+			name: "drops values the handler rejects",
+			onError: func(context.Context, int, error) (int, bool) {
+				return 0, false
+			},
+			want: []int{2, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			got := Collect(ctx, Filter(even, tt.onError)(ctx, Source(ctx, 0, 1, 2, 3, 4)))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestOrRepanic(t *testing.T) {
+	perr := &PanicError{Value: "boom"}
+	defer func() {
+		if r := recover(); r != perr {
+			t.Errorf("Expected the handler to panic with %v, got %v", perr, r)
+		}
+	}()
+	orRepanic[int, int](nil)(context.Background(), 1, perr)
+	t.Error("Expected a nil handler to panic")
+}
+
+func TestBatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		values []int
+		want   [][]int
+	}{
+		{name: "full batches", size: 2, values: []int{1, 2, 3, 4}, want: [][]int{{1, 2}, {3, 4}}},
+		{name: "flushes partial batch", size: 3, values: []int{1, 2, 3, 4}, want: [][]int{{1, 2, 3}, {4}}},
+		{name: "size below one", size: 0, values: []int{1, 2}, want: [][]int{{1}, {2}}},
+		{name: "empty input", size: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			got := Collect(ctx, Batch[int](tt.size)(ctx, Source(ctx, tt.values...)))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if len(got[i]) != len(tt.want[i]) {
+					t.Fatalf("Expected %v, got %v", tt.want, got)
+				}
+				for j := range tt.want[i] {
+					if got[i][j] != tt.want[i][j] {
+						t.Fatalf("Expected %v, got %v", tt.want, got)
+					}
+				}
+			}
+		})
+	}
+}